@@ -0,0 +1,291 @@
+package gotenberg
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"slices"
+	"strconv"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// Job is a request of a batch.
+type Job struct {
+	// ID identifies the job in results and errors; it defaults to the submission index.
+	ID string
+	// Send builds and sends the request with the given context, which is done when the job
+	// times out or the batch is cancelled.
+	Send func(ctx context.Context, c *Client) (*Response, error)
+}
+
+// HTMLJob returns a job converting an HTML document to PDF with Chromium.
+// If configure is not nil, it sets the options of the request.
+func HTMLJob(id string, html io.Reader, configure func(*Chromium)) Job {
+	return Job{ID: id, Send: func(ctx context.Context, c *Client) (*Response, error) {
+		r := c.Chromium().ConvertHTML(ctx, html)
+		if configure != nil {
+			configure(r)
+		}
+		return r.Send()
+	}}
+}
+
+// URLJob returns a job converting a web page to PDF with Chromium.
+// If configure is not nil, it sets the options of the request.
+func URLJob(id, url string, configure func(*Chromium)) Job {
+	return Job{ID: id, Send: func(ctx context.Context, c *Client) (*Response, error) {
+		r := c.Chromium().ConvertURL(ctx, url)
+		if configure != nil {
+			configure(r)
+		}
+		return r.Send()
+	}}
+}
+
+// OfficeJob returns a job converting Office documents to PDF with LibreOffice.
+// If configure is not nil, it sets the options of the request.
+func OfficeJob(id string, files []NamedFile, configure func(*LibreOffice)) Job {
+	return Job{ID: id, Send: func(ctx context.Context, c *Client) (*Response, error) {
+		r := c.LibreOffice().Convert(ctx)
+		for _, f := range files {
+			r.File(f.Name, f.Content)
+		}
+		if configure != nil {
+			configure(r)
+		}
+		return r.Send()
+	}}
+}
+
+// Jobs returns a closed channel yielding the given jobs, for batches of known jobs.
+func Jobs(jobs ...Job) <-chan Job {
+	ch := make(chan Job, len(jobs))
+	for _, j := range jobs {
+		ch <- j
+	}
+	close(ch)
+	return ch
+}
+
+// BatchOptions configures a batch.
+type BatchOptions struct {
+	// Workers is the number of jobs run at once; it defaults to 4.
+	Workers int
+	// JobTimeout bounds each job, reading of the response included; zero means no timeout.
+	JobTimeout time.Duration
+	// Ordered delivers results in submission order instead of completion order.
+	Ordered bool
+	// FailFast cancels the batch on the first failed job.
+	FailFast bool
+}
+
+// BatchResult is the outcome of a job. The caller must close the body of a successful response.
+type BatchResult struct {
+	ID       string
+	Index    int
+	Response *Response
+	// Err is a *JobError when the job failed, a non-successful status included.
+	Err      error
+	Duration time.Duration
+}
+
+// JobError is the failure of a job of a batch.
+type JobError struct {
+	ID  string
+	Err error
+}
+
+// Error implements the error interface.
+func (e *JobError) Error() string {
+	return fmt.Sprintf("job %s: %v", e.ID, e.Err)
+}
+
+// Unwrap returns the error of the job.
+func (e *JobError) Unwrap() error {
+	return e.Err
+}
+
+// Batch runs jobs with bounded concurrency.
+type Batch struct {
+	client  *Client
+	opts    BatchOptions
+	cancel  context.CancelFunc
+	results chan BatchResult
+	errs    []error
+	// bodies counts the delivered bodies not yet closed, which the batch context bounds.
+	bodies sync.WaitGroup
+	// failedFast is set once a failed job cancels the batch, with FailFast.
+	failedFast atomic.Bool
+}
+
+// indexedJob is a job along with its submission index.
+type indexedJob struct {
+	Job
+	index int
+}
+
+// Batch runs the jobs received from the channel until it is closed, the context is done,
+// or, with FailFast, a job fails. Results must be drained from Results; jobs not started
+// when the batch is cancelled have no result.
+func (c *Client) Batch(ctx context.Context, jobs <-chan Job, opts BatchOptions) *Batch {
+	if opts.Workers <= 0 {
+		opts.Workers = 4
+	}
+	ctx, cancel := context.WithCancel(ctx)
+	b := &Batch{client: c, opts: opts, cancel: cancel, results: make(chan BatchResult)}
+
+	queue := make(chan indexedJob)
+	go func() {
+		defer close(queue)
+		for i := 0; ; i++ {
+			var job Job
+			var ok bool
+			select {
+			case <-ctx.Done():
+				return
+			case job, ok = <-jobs:
+				if !ok {
+					return
+				}
+			}
+			if job.ID == "" {
+				job.ID = strconv.Itoa(i)
+			}
+			select {
+			case <-ctx.Done():
+				return
+			case queue <- indexedJob{job, i}:
+			}
+		}
+	}()
+
+	done := make(chan BatchResult)
+	var wg sync.WaitGroup
+	for range opts.Workers {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for job := range queue {
+				if ctx.Err() != nil {
+					continue
+				}
+				done <- b.run(ctx, job)
+			}
+		}()
+	}
+	go func() {
+		wg.Wait()
+		close(done)
+	}()
+
+	go b.deliver(done)
+	return b
+}
+
+// Results returns the channel results are delivered on, closed once the batch is over.
+func (b *Batch) Results() <-chan BatchResult {
+	return b.results
+}
+
+// Cancel cancels the jobs not yet completed, and the reading of the responses not yet closed.
+func (b *Batch) Cancel() {
+	b.cancel()
+}
+
+// Err returns the errors of the failed jobs, joined, once Results is closed.
+// Jobs interrupted by a FailFast cancellation are left out.
+func (b *Batch) Err() error {
+	return errors.Join(b.errs...)
+}
+
+// run runs a job, bounded by the job timeout, and returns its result.
+func (b *Batch) run(ctx context.Context, job indexedJob) BatchResult {
+	result := BatchResult{ID: job.ID, Index: job.index}
+	start := time.Now()
+	defer func() { result.Duration = time.Since(start) }()
+
+	cancel := context.CancelFunc(func() {})
+	if b.opts.JobTimeout > 0 {
+		ctx, cancel = context.WithTimeout(ctx, b.opts.JobTimeout)
+	}
+
+	resp, err := job.Send(ctx, b.client)
+	if err == nil {
+		if err = resp.checkStatus(); err != nil {
+			resp.Body.Close()
+		}
+	}
+	if err != nil {
+		cancel()
+		result.Err = &JobError{ID: job.ID, Err: err}
+		if b.opts.FailFast {
+			b.failedFast.Store(true)
+			b.cancel()
+		}
+		return result
+	}
+
+	// The job context bounds the reading of the body, so it ends when the body is closed.
+	b.bodies.Add(1)
+	resp.Body = &cancelOnClose{ReadCloser: resp.Body, cancel: func() {
+		cancel()
+		b.bodies.Done()
+	}}
+	result.Response = resp
+	return result
+}
+
+// deliver sends the results in completion or submission order, and records the errors.
+// Once every result is delivered and every body closed, it releases the batch context.
+func (b *Batch) deliver(done <-chan BatchResult) {
+	defer func() {
+		close(b.results)
+		b.bodies.Wait()
+		b.cancel()
+	}()
+
+	pending := make(map[int]BatchResult)
+	next := 0
+	for result := range done {
+		if result.Err != nil && !(b.failedFast.Load() && errors.Is(result.Err, context.Canceled)) {
+			b.errs = append(b.errs, result.Err)
+		}
+		if !b.opts.Ordered {
+			b.results <- result
+			continue
+		}
+		pending[result.Index] = result
+		for r, ok := pending[next]; ok; r, ok = pending[next] {
+			b.results <- r
+			delete(pending, next)
+			next++
+		}
+	}
+
+	// Jobs dropped by a cancellation leave gaps: deliver the remaining results in order.
+	indexes := make([]int, 0, len(pending))
+	for i := range pending {
+		indexes = append(indexes, i)
+	}
+	slices.Sort(indexes)
+	for _, i := range indexes {
+		b.results <- pending[i]
+	}
+}
+
+// cancelOnClose cancels a context when the body it wraps is closed.
+type cancelOnClose struct {
+	io.ReadCloser
+	cancel context.CancelFunc
+	once   sync.Once
+}
+
+// Close closes the body and cancels the context, once.
+func (c *cancelOnClose) Close() error {
+	err := c.ReadCloser.Close()
+	c.once.Do(c.cancel)
+	return err
+}
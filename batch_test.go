@@ -0,0 +1,120 @@
+package gotenberg
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+)
+
+// fakeJob returns a job answering its ID after the given delay, or failing if fail is set.
+// A delay of -1 waits until the job is cancelled.
+func fakeJob(id string, delay time.Duration, fail bool) Job {
+	return Job{ID: id, Send: func(ctx context.Context, _ *Client) (*Response, error) {
+		wait := time.After(delay)
+		if delay < 0 {
+			wait = nil
+		}
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		case <-wait:
+		}
+		if fail {
+			return nil, errors.New("conversion failed")
+		}
+		return &Response{Response: &http.Response{
+			StatusCode: http.StatusOK,
+			Body:       io.NopCloser(strings.NewReader(id)),
+		}}, nil
+	}}
+}
+
+func TestBatch(t *testing.T) {
+	tests := []struct {
+		name string
+		opts BatchOptions
+		jobs []Job
+		// want lists the delivered results, the IDs of failed jobs being suffixed with !.
+		want []string
+		// errs lists the IDs of the jobs reported by Err.
+		errs []string
+	}{
+		{
+			name: "completion order",
+			opts: BatchOptions{Workers: 3},
+			jobs: []Job{fakeJob("a", 100*time.Millisecond, false), fakeJob("b", 0, false), fakeJob("c", 50*time.Millisecond, false)},
+			want: []string{"b", "c", "a"},
+		},
+		{
+			name: "ordered",
+			opts: BatchOptions{Workers: 3, Ordered: true},
+			jobs: []Job{fakeJob("a", 100*time.Millisecond, false), fakeJob("b", 0, false), fakeJob("c", 50*time.Millisecond, false)},
+			want: []string{"a", "b", "c"},
+		},
+		{
+			name: "failure",
+			opts: BatchOptions{Workers: 1, Ordered: true},
+			jobs: []Job{fakeJob("a", 0, false), fakeJob("b", 0, true), fakeJob("c", 0, false)},
+			want: []string{"a", "b!", "c"},
+			errs: []string{"b"},
+		},
+		{
+			name: "fail fast skips the next jobs",
+			opts: BatchOptions{Workers: 1, Ordered: true, FailFast: true},
+			jobs: []Job{fakeJob("a", 0, false), fakeJob("b", 0, true), fakeJob("c", 0, false)},
+			want: []string{"a", "b!"},
+			errs: []string{"b"},
+		},
+		{
+			name: "fail fast interrupts the running jobs",
+			opts: BatchOptions{Workers: 2, Ordered: true, FailFast: true},
+			jobs: []Job{fakeJob("a", -1, false), fakeJob("b", 50*time.Millisecond, true)},
+			want: []string{"a!", "b!"},
+			errs: []string{"b"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+			defer cancel()
+			b := (&Client{}).Batch(ctx, Jobs(tt.jobs...), tt.opts)
+
+			var got []string
+			for r := range b.Results() {
+				if r.Err != nil {
+					got = append(got, r.ID+"!")
+					continue
+				}
+				body, err := io.ReadAll(r.Response.Body)
+				r.Response.Body.Close()
+				if err != nil || string(body) != r.ID {
+					t.Errorf("job %s: body = %q, %v", r.ID, body, err)
+				}
+				got = append(got, r.ID)
+			}
+			if !slices.Equal(got, tt.want) {
+				t.Errorf("results = %v, want %v", got, tt.want)
+			}
+
+			var errs []string
+			if err := b.Err(); err != nil {
+				for _, err := range err.(interface{ Unwrap() []error }).Unwrap() {
+					var jobErr *JobError
+					if !errors.As(err, &jobErr) {
+						t.Fatalf("Err() = %v, want *JobError", err)
+					}
+					errs = append(errs, jobErr.ID)
+				}
+			}
+			if !slices.Equal(errs, tt.errs) {
+				t.Errorf("Err() jobs = %v, want %v", errs, tt.errs)
+			}
+		})
+	}
+}
@@ -0,0 +1,129 @@
+package gotenberg
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+)
+
+// Bookmark represents an entry of a PDF document outline.
+// Page numbers are 1-based.
+type Bookmark struct {
+	Title    string     `json:"title"`
+	Page     int        `json:"page"`
+	Children []Bookmark `json:"children,omitempty"`
+}
+
+// BookmarkDocument describes a document taking part in a merge.
+// It is used to generate the outline of the merged PDF with BookmarksForMerge.
+type BookmarkDocument struct {
+	// Title is the title of the top-level bookmark pointing to the document.
+	Title string
+	// Pages is the number of pages of the document.
+	Pages int
+	// Children are the bookmarks of the document, with pages relative to the document itself.
+	Children []Bookmark
+}
+
+// ValidateBookmarks checks that every bookmark has a title and a page number within
+// [1, pageCount], and that children never point before their parent.
+// A pageCount of 0 means the page count is unknown and is not checked.
+func ValidateBookmarks(bookmarks []Bookmark, pageCount int) error {
+	return validateBookmarks("bookmarks", bookmarks, 1, pageCount)
+}
+
+func validateBookmarks(path string, bookmarks []Bookmark, minPage, pageCount int) error {
+	var errs []error
+	for i, b := range bookmarks {
+		p := fmt.Sprintf("%s[%d]", path, i)
+		switch {
+		case b.Title == "":
+			errs = append(errs, fmt.Errorf("%s: empty title", p))
+		case b.Page < 1 || (pageCount > 0 && b.Page > pageCount):
+			errs = append(errs, fmt.Errorf("%s: page %d out of range", p, b.Page))
+		case b.Page < minPage:
+			errs = append(errs, fmt.Errorf("%s: page %d precedes parent page %d", p, b.Page, minPage))
+		}
+		if err := validateBookmarks(p+".children", b.Children, max(b.Page, minPage), pageCount); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// BookmarksForMerge generates the outline of a merged PDF from the documents it is made of,
+// in merge order. Each document gets a top-level bookmark pointing to its first page,
+// and its children are shifted by the number of pages preceding the document.
+func BookmarksForMerge(docs ...BookmarkDocument) ([]Bookmark, error) {
+	bookmarks := make([]Bookmark, 0, len(docs))
+	offset := 0
+	for i, doc := range docs {
+		if doc.Pages < 1 {
+			return nil, fmt.Errorf("document %d (%q): invalid page count %d", i, doc.Title, doc.Pages)
+		}
+		if err := validateBookmarks(fmt.Sprintf("document %d children", i), doc.Children, 1, doc.Pages); err != nil {
+			return nil, err
+		}
+		bookmarks = append(bookmarks, Bookmark{
+			Title:    doc.Title,
+			Page:     offset + 1,
+			Children: shiftBookmarks(doc.Children, offset),
+		})
+		offset += doc.Pages
+	}
+	return bookmarks, nil
+}
+
+// shiftBookmarks returns a copy of the bookmarks with pages shifted by offset.
+func shiftBookmarks(bookmarks []Bookmark, offset int) []Bookmark {
+	if len(bookmarks) == 0 {
+		return nil
+	}
+	shifted := make([]Bookmark, len(bookmarks))
+	for i, b := range bookmarks {
+		shifted[i] = Bookmark{
+			Title:    b.Title,
+			Page:     b.Page + offset,
+			Children: shiftBookmarks(b.Children, offset),
+		}
+	}
+	return shifted
+}
+
+// ReadBookmarks reads the bookmark outline of the given PDFs.
+// The result is keyed by filename.
+func (r *PDFEngines) ReadBookmarks(ctx context.Context, files ...NamedFile) (map[string][]Bookmark, error) {
+	r.BookmarksRead(ctx)
+	for _, f := range files {
+		r.File(f.Name, f.Content)
+	}
+
+	resp, err := r.Send()
+	if err != nil {
+		return nil, err
+	}
+
+	var bookmarks map[string][]Bookmark
+	if err := resp.decodeJSON(&bookmarks); err != nil {
+		return nil, err
+	}
+	return bookmarks, nil
+}
+
+// WriteBookmarks validates the bookmarks and writes them to the given PDF.
+func (r *PDFEngines) WriteBookmarks(ctx context.Context, file NamedFile, bookmarks []Bookmark) (*Response, error) {
+	if err := ValidateBookmarks(bookmarks, 0); err != nil {
+		return nil, err
+	}
+
+	b, err := json.Marshal(bookmarks)
+	if err != nil {
+		return nil, err
+	}
+
+	return r.BookmarksWrite(ctx).
+		File(file.Name, file.Content).
+		Bookmarks(string(b)).
+		Send()
+}
@@ -0,0 +1,275 @@
+package gotenberg
+
+import (
+	"bytes"
+	"container/list"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Cache stores conversion results by a key derived from the request. Caches are best effort:
+// implementations report misses rather than errors.
+//
+// Caches not storing bodies above a size can implement a MaxBodySize() int64 method returning it,
+// so that larger responses are not buffered for them.
+type Cache interface {
+	Get(key string) (*CacheEntry, bool)
+	Set(key string, entry *CacheEntry)
+}
+
+// CacheEntry is a cached conversion result.
+type CacheEntry struct {
+	StatusCode int
+	Header     http.Header
+	Body       []byte
+	Created    time.Time
+}
+
+// Cache sets the cache of conversion results. Requests are cached unless they use webhooks,
+// download their files from URLs, convert or capture URLs, or are sent with NoCache.
+// Files must also be readable again, as the key is computed from their contents: files,
+// byte slices and strings are, while pipes and the responses of pipeline stages are not.
+func (c *Client) Cache(cache Cache) *Client {
+	c.cache = cache
+	return c
+}
+
+// NoCache bypasses the cache and the deduplication of the client, for requests whose inputs are
+// not deterministic.
+func (r *Request) NoCache() *Request {
+	r.noCache = true
+	return r
+}
+
+// cacheable reports whether the result of the request can be cached or shared with identical requests.
+func (r *Request) cacheable() bool {
+	if r.client == nil || r.client.cache == nil && r.client.flights == nil || r.noCache || len(r.Df) > 0 ||
+		strings.HasSuffix(r.route, "/url") || r.headers.Get("Gotenberg-Webhook-Url") != "" {
+		return false
+	}
+	for _, f := range r.files {
+		if !rereadable(f.Content) {
+			return false
+		}
+	}
+	return true
+}
+
+// rereadable reports whether a file content can be hashed then read again when uploaded.
+func rereadable(content io.Reader) bool {
+	switch c := content.(type) {
+	case *bytes.Buffer:
+		return true
+	case io.Seeker:
+		_, err := c.Seek(0, io.SeekCurrent)
+		return err == nil
+	default:
+		return false
+	}
+}
+
+// cacheKey returns a digest of the route, form fields, headers and file contents of the request,
+// rewinding its files once hashed.
+func (r *Request) cacheKey() (string, error) {
+	h := sha256.New()
+	fmt.Fprintf(h, "%s\n", r.route)
+
+	params := make([]string, 0, len(r.params))
+	for _, p := range r.params {
+		params = append(params, strconv.Quote(p.key)+"="+strconv.Quote(p.value))
+	}
+	slices.Sort(params)
+	for _, p := range params {
+		fmt.Fprintf(h, "param %s\n", p)
+	}
+
+	keys := make([]string, 0, len(r.headers))
+	for k := range r.headers {
+		if k != "Gotenberg-Trace" {
+			keys = append(keys, k)
+		}
+	}
+	slices.Sort(keys)
+	for _, k := range keys {
+		fmt.Fprintf(h, "header %q=%q\n", k, r.headers.Get(k))
+	}
+
+	digests := make(map[io.Reader][]byte, len(r.files))
+	for i := range r.files {
+		if b, ok := r.files[i].Content.(*bytes.Buffer); ok {
+			r.files[i].Content = bytes.NewReader(b.Bytes())
+		}
+		content := r.files[i].Content.(io.ReadSeeker)
+		start, err := content.Seek(0, io.SeekCurrent)
+		if err != nil {
+			return "", err
+		}
+		d := sha256.New()
+		if _, err := io.Copy(d, content); err != nil {
+			return "", err
+		}
+		if _, err := content.Seek(start, io.SeekStart); err != nil {
+			return "", err
+		}
+		digests[content] = d.Sum(nil)
+	}
+	for _, f := range r.uploads() {
+		fmt.Fprintf(h, "file %q %q %x\n", f.field, f.Name, digests[f.Content])
+	}
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
+
+// cachedResponse returns a response replaying a cache entry.
+func (r *Request) cachedResponse(e *CacheEntry) *Response {
+	trace := r.headers.Get("Gotenberg-Trace")
+	header := e.Header.Clone()
+	if trace != "" {
+		header.Set("Gotenberg-Trace", trace)
+	}
+	return &Response{
+		Response: &http.Response{
+			Status:        strconv.Itoa(e.StatusCode) + " " + http.StatusText(e.StatusCode),
+			StatusCode:    e.StatusCode,
+			Proto:         "HTTP/1.1",
+			ProtoMajor:    1,
+			ProtoMinor:    1,
+			Header:        header,
+			Body:          io.NopCloser(bytes.NewReader(e.Body)),
+			ContentLength: int64(len(e.Body)),
+		},
+		GotenbergTrace: header.Get("Gotenberg-Trace"),
+		Cached:         true,
+	}
+}
+
+// cacheBody stores a successful response in the cache once its body has been read entirely.
+func (r *Request) cacheBody(key string, resp *http.Response) {
+	if resp.StatusCode < http.StatusOK || resp.StatusCode >= http.StatusMultipleChoices {
+		return
+	}
+	header := make(http.Header)
+	for _, k := range []string{"Content-Type", "Content-Disposition"} {
+		if v := resp.Header.Get(k); v != "" {
+			header.Set(k, v)
+		}
+	}
+	cache := r.client.cache
+	var limit int64
+	if c, ok := cache.(interface{ MaxBodySize() int64 }); ok {
+		limit = c.MaxBodySize()
+	}
+	if limit > 0 && resp.ContentLength > limit {
+		return
+	}
+	resp.Body = &cachingBody{ReadCloser: resp.Body, limit: limit, store: func(body []byte) {
+		cache.Set(key, &CacheEntry{StatusCode: resp.StatusCode, Header: header, Body: body, Created: time.Now()})
+	}}
+}
+
+// cachingBody copies a response body as it is read, and stores it once read entirely.
+// It stops copying once the body exceeds the limit, if not zero.
+type cachingBody struct {
+	io.ReadCloser
+	buf   bytes.Buffer
+	limit int64
+	store func([]byte)
+}
+
+// Read implements the io.Reader interface.
+func (b *cachingBody) Read(p []byte) (int, error) {
+	n, err := b.ReadCloser.Read(p)
+	if b.store == nil {
+		return n, err
+	}
+	if b.limit > 0 && int64(b.buf.Len()+n) > b.limit {
+		b.buf, b.store = bytes.Buffer{}, nil
+		return n, err
+	}
+	b.buf.Write(p[:n])
+	if err == io.EOF {
+		b.store(bytes.Clone(b.buf.Bytes()))
+		b.store = nil
+	}
+	return n, err
+}
+
+// MemoryCache is an in-memory cache evicting the least recently used entries
+// beyond a total body size.
+type MemoryCache struct {
+	maxBytes int64
+	ttl      time.Duration
+
+	mu      sync.Mutex
+	size    int64
+	order   *list.List
+	entries map[string]*list.Element
+}
+
+// memoryItem is an entry of a MemoryCache.
+type memoryItem struct {
+	key   string
+	entry *CacheEntry
+}
+
+// NewMemoryCache returns an in-memory cache holding up to maxBytes of bodies, whose entries
+// expire after ttl. A zero maxBytes or ttl means no limit.
+func NewMemoryCache(maxBytes int64, ttl time.Duration) *MemoryCache {
+	return &MemoryCache{maxBytes: maxBytes, ttl: ttl, order: list.New(), entries: make(map[string]*list.Element)}
+}
+
+// Get implements the Cache interface.
+func (c *MemoryCache) Get(key string) (*CacheEntry, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	el, ok := c.entries[key]
+	if !ok {
+		return nil, false
+	}
+	item := el.Value.(*memoryItem)
+	if c.ttl > 0 && time.Since(item.entry.Created) > c.ttl {
+		c.remove(el)
+		return nil, false
+	}
+	c.order.MoveToFront(el)
+	return item.entry, true
+}
+
+// MaxBodySize returns the size of the largest body the cache stores, zero if unlimited.
+func (c *MemoryCache) MaxBodySize() int64 {
+	return c.maxBytes
+}
+
+// Set implements the Cache interface. Entries larger than the cache are not stored.
+func (c *MemoryCache) Set(key string, entry *CacheEntry) {
+	size := int64(len(entry.Body))
+	if c.maxBytes > 0 && size > c.maxBytes {
+		return
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if el, ok := c.entries[key]; ok {
+		c.remove(el)
+	}
+	c.entries[key] = c.order.PushFront(&memoryItem{key: key, entry: entry})
+	c.size += size
+	for c.maxBytes > 0 && c.size > c.maxBytes {
+		c.remove(c.order.Back())
+	}
+}
+
+// remove removes an entry; c.mu must be held.
+func (c *MemoryCache) remove(el *list.Element) {
+	item := c.order.Remove(el).(*memoryItem)
+	delete(c.entries, item.key)
+	c.size -= int64(len(item.entry.Body))
+}
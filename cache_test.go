@@ -0,0 +1,85 @@
+package gotenberg
+
+import (
+	"bytes"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+)
+
+// mapCache is a cache storing its entries in a map.
+type mapCache map[string]*CacheEntry
+
+func (c mapCache) Get(key string) (*CacheEntry, bool) {
+	e, ok := c[key]
+	return e, ok
+}
+
+func (c mapCache) Set(key string, entry *CacheEntry) {
+	c[key] = entry
+}
+
+// limitedCache is a mapCache declaring the size of the largest body it stores.
+type limitedCache struct {
+	mapCache
+	limit int64
+}
+
+func (c limitedCache) MaxBodySize() int64 {
+	return c.limit
+}
+
+func TestCacheBody(t *testing.T) {
+	small, large := "%PDF-1.7", strings.Repeat("%PDF-1.7", 4)
+	tests := []struct {
+		name          string
+		cache         func() Cache
+		status        int
+		body          string
+		contentLength int64
+		want          bool
+	}{
+		{name: "unlimited", cache: func() Cache { return mapCache{} }, status: http.StatusOK, body: large, contentLength: -1, want: true},
+		{name: "below the limit", cache: func() Cache { return limitedCache{mapCache{}, 16} }, status: http.StatusOK, body: small, contentLength: int64(len(small)), want: true},
+		{name: "below the limit, unknown length", cache: func() Cache { return limitedCache{mapCache{}, 16} }, status: http.StatusOK, body: small, contentLength: -1, want: true},
+		{name: "above the limit", cache: func() Cache { return limitedCache{mapCache{}, 16} }, status: http.StatusOK, body: large, contentLength: int64(len(large))},
+		{name: "above the limit, unknown length", cache: func() Cache { return limitedCache{mapCache{}, 16} }, status: http.StatusOK, body: large, contentLength: -1},
+		{name: "memory cache above its size", cache: func() Cache { return NewMemoryCache(16, 0) }, status: http.StatusOK, body: large, contentLength: -1},
+		{name: "failure", cache: func() Cache { return mapCache{} }, status: http.StatusInternalServerError, body: small, contentLength: -1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cache := tt.cache()
+			r := &Request{client: &Client{cache: cache}}
+			resp := &http.Response{
+				StatusCode:    tt.status,
+				Header:        http.Header{"Content-Type": {"application/pdf"}},
+				Body:          io.NopCloser(strings.NewReader(tt.body)),
+				ContentLength: tt.contentLength,
+			}
+			r.cacheBody("key", resp)
+
+			// Read in small chunks, so that the limit is crossed while reading.
+			var got bytes.Buffer
+			if _, err := io.CopyBuffer(&got, struct{ io.Reader }{resp.Body}, make([]byte, 4)); err != nil {
+				t.Fatal(err)
+			}
+			if got.String() != tt.body {
+				t.Errorf("body = %q, want %q", got.String(), tt.body)
+			}
+			if b, ok := resp.Body.(*cachingBody); ok && !tt.want && b.buf.Len() > 0 {
+				t.Errorf("%d bytes buffered for a body that is not cached", b.buf.Len())
+			}
+
+			e, ok := cache.Get("key")
+			if ok != tt.want {
+				t.Fatalf("cached = %v, want %v", ok, tt.want)
+			}
+			if ok && (string(e.Body) != tt.body || e.Header.Get("Content-Type") != "application/pdf") {
+				t.Errorf("entry = %q %v, want %q", e.Body, e.Header, tt.body)
+			}
+		})
+	}
+}
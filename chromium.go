@@ -10,37 +10,37 @@ import (
 // ConvertHTML creates a request to convert HTML content to PDF.
 // The html parameter should contain the HTML content to be converted.
 func (r *Chromium) ConvertHTML(ctx context.Context, html io.Reader) *Chromium {
-	r.Req = r.HttpStream.Multipart(ctx, "/forms/chromium/convert/html").File("files", "index.html", html)
+	r.open(ctx, "/forms/chromium/convert/html").file("files", "index.html", html)
 	return r
 }
 
 // ConvertURL creates a request to convert a web page at the given URL to PDF.
 func (r *Chromium) ConvertURL(ctx context.Context, url string) *Chromium {
-	r.Req = r.HttpStream.Multipart(ctx, "/forms/chromium/convert/url").Param("url", url)
+	r.open(ctx, "/forms/chromium/convert/url").Param("url", url)
 	return r
 }
 
 // ConvertMarkdown creates a request to convert Markdown content to PDF.
 func (r *Chromium) ConvertMarkdown(ctx context.Context, html io.Reader) *Chromium {
-	r.Req = r.HttpStream.Multipart(ctx, "/forms/chromium/convert/markdown").File("files", "index.html", html)
+	r.open(ctx, "/forms/chromium/convert/markdown").file("files", "index.html", html)
 	return r
 }
 
 // ScreenshotURL creates a request to take a screenshot of a web page at the given URL.
 func (r *Chromium) ScreenshotURL(ctx context.Context, url string) *Chromium {
-	r.Req = r.HttpStream.Multipart(ctx, "/forms/chromium/screenshot/url").Param("url", url)
+	r.open(ctx, "/forms/chromium/screenshot/url").Param("url", url)
 	return r
 }
 
 // ScreenshotHTML creates a request to take a screenshot of HTML content.
 func (r *Chromium) ScreenshotHTML(ctx context.Context, html io.Reader) *Chromium {
-	r.Req = r.HttpStream.Multipart(ctx, "/forms/chromium/screenshot/html").File("files", "index.html", html)
+	r.open(ctx, "/forms/chromium/screenshot/html").file("files", "index.html", html)
 	return r
 }
 
 // ScreenshotMarkdown creates a request to take a screenshot of Markdown content.
 func (r *Chromium) ScreenshotMarkdown(ctx context.Context, html io.Reader) *Chromium {
-	r.Req = r.HttpStream.Multipart(ctx, "/forms/chromium/screenshot/markdown").File("files", "index.html", html)
+	r.open(ctx, "/forms/chromium/screenshot/markdown").file("files", "index.html", html)
 	return r
 }
 
@@ -79,6 +79,12 @@ func (r *Chromium) File(filename string, content io.Reader) *Chromium {
 	return r
 }
 
+// FileSource adds a file provided by a FileSource to the conversion request.
+func (r *Chromium) FileSource(src FileSource) *Chromium {
+	r.Request.FileSource(src)
+	return r
+}
+
 // WebhookURL sets the webhook URL and HTTP method for successful conversions.
 func (r *Chromium) WebhookURL(url, method string) *Chromium {
 	r.Request.WebhookURL(url, method)
@@ -121,6 +127,18 @@ func (r *Chromium) Trace(trace string) *Chromium {
 	return r
 }
 
+// NoCache bypasses the cache of the client for the request.
+func (r *Chromium) NoCache() *Chromium {
+	r.Request.NoCache()
+	return r
+}
+
+// Progress sets a function reporting the upload of the files and the download of the response.
+func (r *Chromium) Progress(fn func(Progress)) *Chromium {
+	r.Request.Progress(fn)
+	return r
+}
+
 // Timeout sets a timeout for the request.
 func (r *Chromium) Timeout(duration time.Duration) *Chromium {
 	r.Request.Timeout(duration)
@@ -133,6 +151,12 @@ func (r *Chromium) Metadata(key, value string) *Chromium {
 	return r
 }
 
+// PDFMetadata sets typed metadata for the operation, preserving value types.
+func (r *Chromium) PDFMetadata(m PDFMetadata) *Chromium {
+	r.Request.PDFMetadata(m)
+	return r
+}
+
 // ScreenshotWidth sets the device screen width in pixels.
 func (r *Chromium) ScreenshotWidth(width int) *Chromium {
 	return r.Param("width", strconv.Itoa(width))
@@ -264,8 +288,12 @@ func (r *Chromium) Scale(value float64) *Chromium {
 }
 
 // NativePageRanges sets the page ranges to print, e.g., '1-5, 8, 11-13'.
+// The selection is validated and normalized; see PageRanges.
 func (r *Chromium) NativePageRanges(value string) *Chromium {
-	return r.Param("nativePageRanges", value)
+	if value, ok := r.pageRanges("nativePageRanges", value, true); ok {
+		r.Param("nativePageRanges", value)
+	}
+	return r
 }
 
 // WaitDelay sets the duration to wait when loading an HTML document before converting it into PDF.
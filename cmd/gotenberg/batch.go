@@ -0,0 +1,518 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"slices"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/nativebpm/gotenberg/v8"
+)
+
+// manifest describes the jobs run by the batch command.
+//
+//	{
+//	  "concurrency": 4,
+//	  "jobs": [
+//	    {"id": "cover", "command": "html", "inputs": ["cover.html"], "options": {"paper": "A4"}, "output": "out/cover.pdf"},
+//	    {"id": "body", "command": "office", "inputs": ["body.docx"], "output": "out/body.pdf"},
+//	    {"id": "book", "command": "merge", "inputs": ["@cover", "@body"], "output": "out/book.pdf"}
+//	  ]
+//	}
+//
+// Commands are the ones of the tool; a command taking an action, such as "metadata write",
+// includes it. Options are the flags of the command, without dash: strings, numbers and
+// booleans, or lists for repeated flags. Inputs of the form @id are the output of another job,
+// which the job then depends on; DependsOn adds dependencies that are not inputs.
+// Relative paths, in inputs, outputs and options, are relative to the manifest. Only conversion
+// commands take an output, which is ignored for other ones, such as health, that run every time.
+type manifest struct {
+	Concurrency int   `json:"concurrency"`
+	Jobs        []job `json:"jobs"`
+}
+
+// job is a command run by the batch command.
+type job struct {
+	ID        string         `json:"id"`
+	Command   string         `json:"command"`
+	Inputs    []string       `json:"inputs"`
+	Options   map[string]any `json:"options"`
+	Output    string         `json:"output"`
+	DependsOn []string       `json:"depends_on"`
+}
+
+// Job statuses, as reported.
+const (
+	statusDone     = "done"
+	statusUpToDate = "up-to-date"
+	statusFailed   = "failed"
+	statusSkipped  = "skipped"
+)
+
+// jobReport is the outcome of a job.
+type jobReport struct {
+	ID         string    `json:"id"`
+	Command    string    `json:"command"`
+	Output     string    `json:"output,omitempty"`
+	Status     string    `json:"status"`
+	Server     string    `json:"server,omitempty"`
+	Trace      string    `json:"trace,omitempty"`
+	Hash       string    `json:"hash,omitempty"`
+	Started    time.Time `json:"started"`
+	DurationMS int64     `json:"duration_ms"`
+	Error      string    `json:"error,omitempty"`
+}
+
+// batchReport is the machine-readable report of the batch command.
+type batchReport struct {
+	Manifest   string         `json:"manifest"`
+	Started    time.Time      `json:"started"`
+	DurationMS int64          `json:"duration_ms"`
+	Summary    map[string]int `json:"summary"`
+	Jobs       []jobReport    `json:"jobs"`
+}
+
+func runBatch(ctx context.Context, e *env, args []string) error {
+	fs := e.flags("batch", "[flags] manifest.json")
+	concurrency := fs.Int("concurrency", 0, "number of jobs run at once (default the manifest's, or 4)")
+	reportFile := fs.String("report", "-", "`file` the JSON report is written to; - writes to standard output")
+	stateFile := fs.String("state", "", "`file` recording the input hashes of completed jobs (default manifest.state.json)")
+	force := fs.Bool("force", false, "run every job, even if its output is up to date")
+	args, err := fs.parse(args)
+	if err != nil {
+		return err
+	}
+	if len(args) != 1 {
+		return fs.usageError("expected a manifest file")
+	}
+
+	b, err := readManifest(args[0])
+	if err != nil {
+		return err
+	}
+	if *concurrency > 0 {
+		b.concurrency = *concurrency
+	}
+	if *stateFile != "" {
+		b.statePath = *stateFile
+	}
+	b.force = *force
+	if err := b.connect(e); err != nil {
+		return err
+	}
+
+	report := b.run(ctx)
+	if err := writeReport(e, report, *reportFile); err != nil {
+		return err
+	}
+	if n := report.Summary[statusFailed]; n > 0 {
+		return fmt.Errorf("%d of %d jobs failed", n, len(report.Jobs))
+	}
+	return nil
+}
+
+// batch runs the jobs of a manifest.
+type batch struct {
+	path        string
+	dir         string
+	jobs        []job
+	index       map[string]int
+	concurrency int
+	statePath   string
+	force       bool
+	clients     []*gotenberg.Client
+	urls        []string
+
+	mu    sync.Mutex
+	state map[string]string
+}
+
+// readManifest reads and checks a manifest.
+func readManifest(path string) (*batch, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var m manifest
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(&m); err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+
+	b := &batch{
+		path:        path,
+		dir:         filepath.Dir(path),
+		jobs:        m.Jobs,
+		index:       make(map[string]int),
+		concurrency: m.Concurrency,
+		statePath:   strings.TrimSuffix(path, filepath.Ext(path)) + ".state.json",
+	}
+	if b.concurrency <= 0 {
+		b.concurrency = 4
+	}
+
+	for i, j := range b.jobs {
+		switch {
+		case j.ID == "":
+			return nil, fmt.Errorf("%s: job %d has no id", path, i+1)
+		case jobCommand(j) == nil:
+			return nil, fmt.Errorf("%s: job %s has an unknown command %q", path, j.ID, j.Command)
+		case j.Output == "" && jobCommand(j).converts:
+			return nil, fmt.Errorf("%s: job %s has no output", path, j.ID)
+		}
+		if _, ok := b.index[j.ID]; ok {
+			return nil, fmt.Errorf("%s: duplicate job id %s", path, j.ID)
+		}
+		b.index[j.ID] = i
+	}
+	for _, j := range b.jobs {
+		for _, dep := range b.dependencies(j) {
+			if _, ok := b.index[dep]; !ok {
+				return nil, fmt.Errorf("%s: job %s depends on unknown job %s", path, j.ID, dep)
+			}
+		}
+	}
+	if cycle := b.cycle(); cycle != nil {
+		return nil, fmt.Errorf("%s: dependency cycle: %s", path, strings.Join(cycle, " -> "))
+	}
+	return b, nil
+}
+
+// dependencies returns the ids of the jobs j depends on.
+func (b *batch) dependencies(j job) []string {
+	deps := slices.Clone(j.DependsOn)
+	for _, in := range j.Inputs {
+		if id, ok := strings.CutPrefix(in, "@"); ok && !slices.Contains(deps, id) {
+			deps = append(deps, id)
+		}
+	}
+	return deps
+}
+
+// cycle returns a dependency cycle, if any.
+func (b *batch) cycle() []string {
+	const (
+		unvisited = iota
+		visiting
+		visited
+	)
+	marks := make([]int, len(b.jobs))
+	var path []string
+	var visit func(i int) []string
+	visit = func(i int) []string {
+		switch marks[i] {
+		case visiting:
+			start := slices.Index(path, b.jobs[i].ID)
+			return append(slices.Clone(path[start:]), b.jobs[i].ID)
+		case visited:
+			return nil
+		}
+		marks[i] = visiting
+		path = append(path, b.jobs[i].ID)
+		for _, dep := range b.dependencies(b.jobs[i]) {
+			if c := visit(b.index[dep]); c != nil {
+				return c
+			}
+		}
+		path = path[:len(path)-1]
+		marks[i] = visited
+		return nil
+	}
+	for i := range b.jobs {
+		if c := visit(i); c != nil {
+			return c
+		}
+	}
+	return nil
+}
+
+// connect creates a client per server; jobs are spread across servers.
+func (b *batch) connect(e *env) error {
+	for _, u := range e.urls {
+		c, err := gotenberg.NewClient(e.httpClient, u)
+		if err != nil {
+			return err
+		}
+		b.clients = append(b.clients, c)
+		b.urls = append(b.urls, u)
+	}
+	return nil
+}
+
+// resolve returns a path relative to the manifest.
+func (b *batch) resolve(path string) string {
+	if filepath.IsAbs(path) {
+		return path
+	}
+	return filepath.Join(b.dir, path)
+}
+
+// args returns the command-line arguments of a job, with the given trace identifier for
+// conversion commands.
+func (b *batch) args(j job, trace string) []string {
+	fields := strings.Fields(j.Command)
+	args := slices.Clone(fields[1:])
+
+	keys := make([]string, 0, len(j.Options))
+	for k := range j.Options {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	for _, k := range keys {
+		values, ok := j.Options[k].([]any)
+		if !ok {
+			values = []any{j.Options[k]}
+		}
+		for _, v := range values {
+			switch v := v.(type) {
+			case string:
+				args = append(args, "-"+k, b.optionPath(v))
+			default:
+				args = append(args, fmt.Sprintf("-%s=%v", k, v))
+			}
+		}
+	}
+
+	if jobCommand(j).converts {
+		args = append(args, "-o", b.resolve(j.Output), "-trace", trace)
+	}
+	args = append(args, "--")
+	for _, in := range j.Inputs {
+		args = append(args, b.input(in))
+	}
+	return args
+}
+
+// optionPath resolves an option value naming a file relative to the manifest.
+func (b *batch) optionPath(v string) string {
+	if p := b.resolve(v); !filepath.IsAbs(v) && isFile(p) {
+		return p
+	}
+	return v
+}
+
+// input resolves an input: the output of a job, a URL or a file.
+func (b *batch) input(in string) string {
+	if id, ok := strings.CutPrefix(in, "@"); ok {
+		return b.resolve(b.jobs[b.index[id]].Output)
+	}
+	if strings.Contains(in, "://") {
+		return in
+	}
+	return b.resolve(in)
+}
+
+// isFile reports whether path names a regular file.
+func isFile(path string) bool {
+	info, err := os.Stat(path)
+	return err == nil && info.Mode().IsRegular()
+}
+
+// hash returns a digest of everything a job's output depends on: its command, options and output,
+// and the content of its input files and of the files named by its options.
+func (b *batch) hash(j job, args []string) (string, error) {
+	h := sha256.New()
+	fmt.Fprintf(h, "%s\x00%s\x00", j.Command, b.resolve(j.Output))
+	options, _ := json.Marshal(j.Options)
+	h.Write(options)
+
+	files := make([]string, 0, len(args))
+	for _, in := range j.Inputs {
+		files = append(files, b.input(in))
+	}
+	for _, a := range args {
+		if isFile(a) && !slices.Contains(files, a) && a != b.resolve(j.Output) {
+			files = append(files, a)
+		}
+	}
+	for _, f := range files {
+		fmt.Fprintf(h, "\x00%s\x00", f)
+		if strings.Contains(f, "://") {
+			continue
+		}
+		file, err := os.Open(f)
+		if err != nil {
+			return "", err
+		}
+		_, err = io.Copy(h, file)
+		file.Close()
+		if err != nil {
+			return "", err
+		}
+	}
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
+
+// loadState reads the hashes of previously completed jobs.
+func (b *batch) loadState() {
+	b.state = make(map[string]string)
+	if data, err := os.ReadFile(b.statePath); err == nil {
+		_ = json.Unmarshal(data, &b.state)
+	}
+}
+
+// record saves the hash of a completed job.
+func (b *batch) record(id, hash string) error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.state[id] = hash
+	data, err := json.MarshalIndent(b.state, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(b.statePath, data, 0o644)
+}
+
+// upToDate reports whether a job's output exists and was produced from the same inputs.
+func (b *batch) upToDate(j job, hash string) bool {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return !b.force && b.state[j.ID] == hash && isFile(b.resolve(j.Output))
+}
+
+// run runs the jobs, each as soon as its dependencies are done, and reports their outcome.
+func (b *batch) run(ctx context.Context) *batchReport {
+	b.loadState()
+	report := &batchReport{Manifest: b.path, Started: time.Now(), Summary: make(map[string]int)}
+	report.Jobs = make([]jobReport, len(b.jobs))
+
+	done := make([]chan struct{}, len(b.jobs))
+	for i := range done {
+		done[i] = make(chan struct{})
+	}
+	slots := make(chan int, b.concurrency)
+	for i := range b.concurrency {
+		slots <- i
+	}
+
+	var wg sync.WaitGroup
+	for i, j := range b.jobs {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			defer close(done[i])
+
+			r := &report.Jobs[i]
+			*r = jobReport{ID: j.ID, Command: j.Command, Started: time.Now()}
+			if jobCommand(j).converts {
+				r.Output = b.resolve(j.Output)
+			}
+			for _, dep := range b.dependencies(j) {
+				<-done[b.index[dep]]
+				if status := report.Jobs[b.index[dep]].Status; status == statusFailed || status == statusSkipped {
+					r.Status, r.Error = statusSkipped, "dependency "+dep+" "+status
+					return
+				}
+			}
+
+			slot := <-slots
+			defer func() { slots <- slot }()
+			b.runJob(ctx, j, slot, r)
+		}()
+	}
+	wg.Wait()
+
+	for _, r := range report.Jobs {
+		report.Summary[r.Status]++
+	}
+	report.DurationMS = time.Since(report.Started).Milliseconds()
+	return report
+}
+
+// runJob runs a job on the server assigned to its slot.
+func (b *batch) runJob(ctx context.Context, j job, slot int, r *jobReport) {
+	r.Started = time.Now()
+	defer func() { r.DurationMS = time.Since(r.Started).Milliseconds() }()
+
+	converts := jobCommand(j).converts
+	if converts {
+		r.Trace = traceID(j)
+	}
+	args := b.args(j, r.Trace)
+	hash, err := b.hash(j, args)
+	if err != nil {
+		r.Status, r.Error = statusFailed, err.Error()
+		return
+	}
+	r.Hash = hash
+	if converts {
+		if b.upToDate(j, hash) {
+			r.Status, r.Trace = statusUpToDate, ""
+			return
+		}
+		if err := os.MkdirAll(filepath.Dir(r.Output), 0o755); err != nil {
+			r.Status, r.Error = statusFailed, err.Error()
+			return
+		}
+	}
+
+	server := slot % len(b.clients)
+	r.Server = b.urls[server]
+	var stderr bytes.Buffer
+	e := &env{
+		client: b.clients[server],
+		stdin:  strings.NewReader(""),
+		stdout: io.Discard,
+		stderr: &stderr,
+	}
+	if err := jobCommand(j).run(ctx, e, args); err != nil {
+		if errors.Is(err, errUsage) {
+			err = errors.New(strings.TrimSpace(strings.SplitN(stderr.String(), "\n", 2)[0]))
+		}
+		r.Status, r.Error = statusFailed, err.Error()
+		return
+	}
+
+	r.Status = statusDone
+	if err := b.record(j.ID, hash); err != nil {
+		r.Error = "state not saved: " + err.Error()
+	}
+}
+
+// jobCommand returns the command run by a job, or nil if it is unknown or cannot run as a job:
+// batch and watch, which never returns.
+func jobCommand(j job) *command {
+	fields := strings.Fields(j.Command)
+	if len(fields) == 0 || fields[0] == "batch" || fields[0] == "watch" {
+		return nil
+	}
+	return findCommand(fields[0])
+}
+
+// traceID returns the trace identifier of a job: the one set in its options, or a unique one.
+func traceID(j job) string {
+	if t, ok := j.Options["trace"].(string); ok && t != "" {
+		return t
+	}
+	b := make([]byte, 4)
+	_, _ = rand.Read(b)
+	return j.ID + "-" + hex.EncodeToString(b)
+}
+
+// writeReport writes the report as indented JSON.
+func writeReport(e *env, report *batchReport, path string) error {
+	data, err := json.MarshalIndent(report, "", "  ")
+	if err != nil {
+		return err
+	}
+	data = append(data, '\n')
+	if path == "" || path == "-" {
+		_, err := e.stdout.Write(data)
+		return err
+	}
+	return os.WriteFile(path, data, 0o644)
+}
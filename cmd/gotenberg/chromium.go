@@ -0,0 +1,358 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/nativebpm/gotenberg/v8"
+)
+
+// waitFlags are the flags delaying Chromium captures.
+type waitFlags struct {
+	delay      string
+	expression string
+	selector   string
+}
+
+// register adds the flags to the flag set.
+func (f *waitFlags) register(fs *flagSet) {
+	fs.StringVar(&f.delay, "wait-delay", "", "`duration` to wait before capturing, e.g. 2s")
+	fs.StringVar(&f.expression, "wait-for-expression", "", "JavaScript `expression` to wait for before capturing")
+	fs.StringVar(&f.selector, "wait-for-selector", "", "CSS `selector` to wait for before capturing")
+}
+
+// apply sets the flags on the request.
+func (f *waitFlags) apply(r *gotenberg.Chromium) {
+	if f.delay != "" {
+		r.WaitDelay(f.delay)
+	}
+	if f.expression != "" {
+		r.WaitForExpression(f.expression)
+	}
+	if f.selector != "" {
+		r.WaitForSelector(f.selector)
+	}
+}
+
+// pageFlags are the flags of Chromium PDF conversions.
+type pageFlags struct {
+	requestFlags
+	waitFlags
+	assets          listFlag
+	paper           string
+	paperWidth      float64
+	paperHeight     float64
+	marginTop       float64
+	marginRight     float64
+	marginBottom    float64
+	marginLeft      float64
+	scale           float64
+	pageRanges      string
+	landscape       bool
+	singlePage      bool
+	printBackground bool
+	omitBackground  bool
+	preferCSSSize   bool
+	outline         bool
+	tagged          bool
+}
+
+// register adds the flags to the flag set.
+func (f *pageFlags) register(fs *flagSet) {
+	f.requestFlags.register(fs)
+	f.waitFlags.register(fs)
+	fs.Var(&f.assets, "asset", "`file` referenced by the page, such as an image or a stylesheet; may be repeated")
+	fs.StringVar(&f.paper, "paper", "", "paper `size`: A4, A6 or Letter")
+	fs.Float64Var(&f.paperWidth, "paper-width", 0, "paper `width` in inches")
+	fs.Float64Var(&f.paperHeight, "paper-height", 0, "paper `height` in inches")
+	fs.Float64Var(&f.marginTop, "margin-top", 0, "top `margin` in inches")
+	fs.Float64Var(&f.marginRight, "margin-right", 0, "right `margin` in inches")
+	fs.Float64Var(&f.marginBottom, "margin-bottom", 0, "bottom `margin` in inches")
+	fs.Float64Var(&f.marginLeft, "margin-left", 0, "left `margin` in inches")
+	fs.Float64Var(&f.scale, "scale", 0, "rendering `scale`, between 0.1 and 2")
+	fs.StringVar(&f.pageRanges, "page-ranges", "", "page `ranges` to print, e.g. '1-5, 8'")
+	fs.BoolVar(&f.landscape, "landscape", false, "use the landscape orientation")
+	fs.BoolVar(&f.singlePage, "single-page", false, "print the content on a single page")
+	fs.BoolVar(&f.printBackground, "print-background", false, "print the background graphics")
+	fs.BoolVar(&f.omitBackground, "omit-background", false, "hide the default white background")
+	fs.BoolVar(&f.preferCSSSize, "prefer-css-page-size", false, "use the page size defined by CSS")
+	fs.BoolVar(&f.outline, "outline", false, "generate the document outline")
+	fs.BoolVar(&f.tagged, "tagged", false, "generate a tagged, accessible PDF")
+}
+
+// apply sets the flags given to the command on the request.
+func (f *pageFlags) apply(fs *flagSet, e *env, r *gotenberg.Chromium) (*inputs, error) {
+	switch strings.ToLower(f.paper) {
+	case "":
+	case "a4":
+		r.PaperSizeA4()
+	case "a6":
+		r.PaperSizeA6()
+	case "letter":
+		r.PaperSizeLetter()
+	default:
+		return nil, fs.usageError("unknown paper size %q", f.paper)
+	}
+
+	for _, v := range []struct {
+		name  string
+		value float64
+		set   func(float64) *gotenberg.Chromium
+	}{
+		{"paper-width", f.paperWidth, r.PaperWidth},
+		{"paper-height", f.paperHeight, r.PaperHeight},
+		{"margin-top", f.marginTop, r.MarginTop},
+		{"margin-right", f.marginRight, r.MarginRight},
+		{"margin-bottom", f.marginBottom, r.MarginBottom},
+		{"margin-left", f.marginLeft, r.MarginLeft},
+		{"scale", f.scale, r.Scale},
+	} {
+		if fs.set[v.name] {
+			v.set(v.value)
+		}
+	}
+
+	for _, v := range []struct {
+		value bool
+		set   func() *gotenberg.Chromium
+	}{
+		{f.landscape, r.Landscape},
+		{f.singlePage, r.SinglePage},
+		{f.printBackground, r.PrintBackground},
+		{f.omitBackground, r.OmitBackground},
+		{f.preferCSSSize, r.PreferCssPageSize},
+		{f.outline, r.GenerateDocumentOutline},
+		{f.tagged, r.GenerateTaggedPdf},
+	} {
+		if v.value {
+			v.set()
+		}
+	}
+
+	if f.pageRanges != "" {
+		r.NativePageRanges(f.pageRanges)
+	}
+	f.waitFlags.apply(r)
+	return addAssets(e, r, f.assets)
+}
+
+// addAssets adds the asset files to the request.
+func addAssets(e *env, r *gotenberg.Chromium, assets []string) (*inputs, error) {
+	in, err := e.open(assets, "")
+	if err != nil {
+		return nil, err
+	}
+	for _, f := range in.files {
+		r.File(f.Name, f.Content)
+	}
+	return in, nil
+}
+
+// singleInput returns the only argument of a command reading one file, "-" by default.
+func singleInput(fs *flagSet, args []string) (string, error) {
+	switch len(args) {
+	case 0:
+		return "-", nil
+	case 1:
+		return args[0], nil
+	default:
+		return "", fs.usageError("expected a single input file")
+	}
+}
+
+func runHTML(ctx context.Context, e *env, args []string) error {
+	fs := e.flags("html", "[flags] [index.html|-]")
+	var f pageFlags
+	f.register(fs)
+	args, err := fs.parse(args)
+	if err != nil {
+		return err
+	}
+	input, err := singleInput(fs, args)
+	if err != nil {
+		return err
+	}
+
+	in, err := e.open([]string{input}, "index.html")
+	if err != nil {
+		return err
+	}
+	defer in.Close()
+
+	r := e.client.Chromium().ConvertHTML(ctx, in.files[0].Content)
+	assets, err := f.apply(fs, e, r)
+	if err != nil {
+		return err
+	}
+	defer assets.Close()
+	return e.send(r.Request, &f.requestFlags, r.Send)
+}
+
+func runURL(ctx context.Context, e *env, args []string) error {
+	fs := e.flags("url", "[flags] URL")
+	var f pageFlags
+	f.register(fs)
+	args, err := fs.parse(args)
+	if err != nil {
+		return err
+	}
+	if len(args) != 1 {
+		return fs.usageError("expected a single URL")
+	}
+
+	r := e.client.Chromium().ConvertURL(ctx, args[0])
+	assets, err := f.apply(fs, e, r)
+	if err != nil {
+		return err
+	}
+	defer assets.Close()
+	return e.send(r.Request, &f.requestFlags, r.Send)
+}
+
+// markdownWrapper returns an HTML page rendering the given Markdown files in order.
+func markdownWrapper(files []gotenberg.NamedFile) io.Reader {
+	var b strings.Builder
+	b.WriteString("<!doctype html>\n<html>\n<head><meta charset=\"utf-8\"></head>\n<body>\n")
+	for _, f := range files {
+		fmt.Fprintf(&b, "{{ toHTML %q }}\n", f.Name)
+	}
+	b.WriteString("</body>\n</html>\n")
+	return strings.NewReader(b.String())
+}
+
+// markdownInputs opens the Markdown files and the HTML page rendering them:
+// the given template, or a page rendering the files in order.
+func markdownInputs(fs *flagSet, e *env, args []string, template string) (io.Reader, *inputs, error) {
+	if len(args) == 0 {
+		return nil, nil, fs.usageError("expected Markdown files")
+	}
+	in, err := e.open(args, "index.md")
+	if err != nil {
+		return nil, nil, err
+	}
+	if template == "" {
+		return markdownWrapper(in.files), in, nil
+	}
+
+	t, err := e.open([]string{template}, "index.html")
+	if err != nil {
+		in.Close()
+		return nil, nil, err
+	}
+	in.closer = append(in.closer, t)
+	return t.files[0].Content, in, nil
+}
+
+func runMarkdown(ctx context.Context, e *env, args []string) error {
+	fs := e.flags("markdown", "[flags] file.md...")
+	var f pageFlags
+	f.register(fs)
+	template := fs.String("template", "", "HTML `file` rendering the Markdown files with {{ toHTML \"file.md\" }}")
+	args, err := fs.parse(args)
+	if err != nil {
+		return err
+	}
+
+	page, in, err := markdownInputs(fs, e, args, *template)
+	if err != nil {
+		return err
+	}
+	defer in.Close()
+
+	r := e.client.Chromium().ConvertMarkdown(ctx, page)
+	for _, md := range in.files {
+		r.File(md.Name, md.Content)
+	}
+	assets, err := f.apply(fs, e, r)
+	if err != nil {
+		return err
+	}
+	defer assets.Close()
+	return e.send(r.Request, &f.requestFlags, r.Send)
+}
+
+func runScreenshot(ctx context.Context, e *env, args []string) error {
+	fs := e.flags("screenshot", "[flags] {URL | index.html | -markdown file.md... | -}")
+	var f requestFlags
+	var wait waitFlags
+	var assets listFlag
+	f.register(fs)
+	wait.register(fs)
+	fs.Var(&assets, "asset", "`file` referenced by the page, such as an image or a stylesheet; may be repeated")
+	markdown := fs.Bool("markdown", false, "capture Markdown files")
+	template := fs.String("template", "", "with -markdown, HTML `file` rendering the Markdown files")
+	width := fs.Int("width", 0, "viewport `width` in pixels")
+	height := fs.Int("height", 0, "viewport `height` in pixels")
+	clip := fs.Bool("clip", false, "clip the capture to the viewport")
+	format := fs.String("format", "", "image `format`: png, jpeg or webp")
+	quality := fs.Int("quality", 0, "JPEG `quality`, from 0 to 100")
+	omitBackground := fs.Bool("omit-background", false, "hide the default white background")
+	optimize := fs.Bool("optimize-for-speed", false, "favor encoding speed over image size")
+	scale := fs.Float64("device-scale-factor", 0, "device scale `factor`")
+	args, err := fs.parse(args)
+	if err != nil {
+		return err
+	}
+
+	r := e.client.Chromium()
+	switch {
+	case *markdown:
+		page, in, err := markdownInputs(fs, e, args, *template)
+		if err != nil {
+			return err
+		}
+		defer in.Close()
+		r.ScreenshotMarkdown(ctx, page)
+		for _, md := range in.files {
+			r.File(md.Name, md.Content)
+		}
+	case len(args) == 1 && (strings.HasPrefix(args[0], "http://") || strings.HasPrefix(args[0], "https://")):
+		r.ScreenshotURL(ctx, args[0])
+	default:
+		input, err := singleInput(fs, args)
+		if err != nil {
+			return err
+		}
+		in, err := e.open([]string{input}, "index.html")
+		if err != nil {
+			return err
+		}
+		defer in.Close()
+		r.ScreenshotHTML(ctx, in.files[0].Content)
+	}
+
+	if fs.set["width"] {
+		r.ScreenshotWidth(*width)
+	}
+	if fs.set["height"] {
+		r.ScreenshotHeight(*height)
+	}
+	if fs.set["clip"] {
+		r.ScreenshotClip(*clip)
+	}
+	if *format != "" {
+		r.ScreenshotFormat(*format)
+	}
+	if fs.set["quality"] {
+		r.ScreenshotQuality(*quality)
+	}
+	if fs.set["omit-background"] {
+		r.ScreenshotOmitBackground(*omitBackground)
+	}
+	if fs.set["optimize-for-speed"] {
+		r.ScreenshotOptimizeForSpeed(*optimize)
+	}
+	if fs.set["device-scale-factor"] {
+		r.ScreenshotDeviceScaleFactor(*scale)
+	}
+	wait.apply(r)
+
+	in, err := addAssets(e, r, assets)
+	if err != nil {
+		return err
+	}
+	defer in.Close()
+	return e.send(r.Request, &f, r.Send)
+}
@@ -0,0 +1,153 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"strings"
+
+	"github.com/nativebpm/gotenberg/v8"
+)
+
+// listFlag is a flag that may be repeated.
+type listFlag []string
+
+// String implements the flag.Value interface.
+func (l *listFlag) String() string {
+	return strings.Join(*l, ", ")
+}
+
+// Set implements the flag.Value interface.
+func (l *listFlag) Set(value string) error {
+	*l = append(*l, value)
+	return nil
+}
+
+// pairs splits the key=value elements of the list.
+func (l listFlag) pairs() ([][2]string, error) {
+	pairs := make([][2]string, 0, len(l))
+	for _, s := range l {
+		key, value, ok := strings.Cut(s, "=")
+		if !ok || key == "" {
+			return nil, fmt.Errorf("%q is not of the form key=value", s)
+		}
+		pairs = append(pairs, [2]string{key, value})
+	}
+	return pairs, nil
+}
+
+// flagSet is the flag set of a command, along with the flags it has been given.
+type flagSet struct {
+	*flag.FlagSet
+	set map[string]bool
+}
+
+// flags returns the flag set of a command; usage describes its arguments.
+func (e *env) flags(name, usage string) *flagSet {
+	fs := &flagSet{FlagSet: flag.NewFlagSet(name, flag.ContinueOnError)}
+	fs.SetOutput(e.stderr)
+	fs.Usage = func() {
+		fmt.Fprintf(e.stderr, "Usage: gotenberg %s %s\n\nFlags:\n", name, usage)
+		fs.PrintDefaults()
+	}
+	return fs
+}
+
+// parse parses the flags, which may be interleaved with arguments, and returns the arguments.
+// Arguments following "--" are never read as flags.
+func (fs *flagSet) parse(args []string) ([]string, error) {
+	var rest []string
+	for len(args) > 0 {
+		if err := fs.Parse(args); err != nil {
+			if err == flag.ErrHelp {
+				return nil, err
+			}
+			return nil, errUsage
+		}
+		remaining := fs.Args()
+		if consumed := len(args) - len(remaining); consumed > 0 && args[consumed-1] == "--" {
+			rest = append(rest, remaining...)
+			break
+		}
+		if len(remaining) > 0 {
+			rest = append(rest, remaining[0])
+			remaining = remaining[1:]
+		}
+		args = remaining
+	}
+
+	fs.set = make(map[string]bool)
+	fs.Visit(func(f *flag.Flag) { fs.set[f.Name] = true })
+	return rest, nil
+}
+
+// usageError prints the usage of the command and returns errUsage.
+func (fs *flagSet) usageError(format string, args ...any) error {
+	fmt.Fprintf(fs.Output(), format+"\n", args...)
+	fs.Usage()
+	return errUsage
+}
+
+// requestFlags are the flags shared by every conversion command.
+type requestFlags struct {
+	output          string
+	outputFilename  string
+	trace           string
+	webhookURL      string
+	webhookErrorURL string
+	webhookMethod   string
+	headers         listFlag
+	params          listFlag
+	metadata        listFlag
+	downloadFrom    listFlag
+}
+
+// register adds the flags to the flag set.
+func (f *requestFlags) register(fs *flagSet) {
+	fs.StringVar(&f.output, "o", "-", "output `file` or directory; - writes to standard output")
+	fs.StringVar(&f.outputFilename, "output-filename", "", "`name` of the output file returned by the server")
+	fs.StringVar(&f.trace, "trace", "", "request trace `identifier`")
+	fs.StringVar(&f.webhookURL, "webhook-url", "", "send the result asynchronously to this `URL`")
+	fs.StringVar(&f.webhookErrorURL, "webhook-error-url", "", "send errors asynchronously to this `URL`")
+	fs.StringVar(&f.webhookMethod, "webhook-method", "POST", "HTTP `method` of the webhook calls")
+	fs.Var(&f.headers, "header", "HTTP header as `key=value`; may be repeated")
+	fs.Var(&f.params, "param", "raw form field as `key=value`; may be repeated")
+	fs.Var(&f.metadata, "metadata", "PDF metadata as `key=value`; may be repeated")
+	fs.Var(&f.downloadFrom, "download-from", "`URL` of a file downloaded by the server; may be repeated")
+}
+
+// apply sets the flags on the request.
+func (f *requestFlags) apply(r *gotenberg.Request) error {
+	if f.outputFilename != "" {
+		r.OutputFilename(f.outputFilename)
+	}
+	if f.trace != "" {
+		r.Trace(f.trace)
+	}
+	if f.webhookURL != "" {
+		r.WebhookURL(f.webhookURL, f.webhookMethod)
+	}
+	if f.webhookErrorURL != "" {
+		r.WebhookErrorURL(f.webhookErrorURL, f.webhookMethod)
+	}
+	for _, u := range f.downloadFrom {
+		r.DownloadFrom(u, nil)
+	}
+
+	for _, list := range []struct {
+		values listFlag
+		set    func(key, value string) *gotenberg.Request
+	}{
+		{f.headers, r.Header},
+		{f.params, r.Param},
+		{f.metadata, r.Metadata},
+	} {
+		pairs, err := list.values.pairs()
+		if err != nil {
+			return err
+		}
+		for _, p := range pairs {
+			list.set(p[0], p[1])
+		}
+	}
+	return nil
+}
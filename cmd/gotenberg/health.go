@@ -0,0 +1,53 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+)
+
+func runHealth(ctx context.Context, e *env, args []string) error {
+	fs := e.flags("health", "[flags] [module...]")
+	wait := fs.Duration("wait", 0, "wait up to `duration` for the server, or the given modules, to be up")
+	modules, err := fs.parse(args)
+	if err != nil {
+		return err
+	}
+
+	if *wait > 0 {
+		waitCtx, cancel := context.WithTimeout(ctx, *wait)
+		err := e.client.WaitUntilHealthy(waitCtx, modules...)
+		cancel()
+		if err != nil {
+			return err
+		}
+	}
+
+	health, err := e.client.GetHealth(ctx)
+	if err != nil {
+		return err
+	}
+	enc := json.NewEncoder(e.stdout)
+	enc.SetIndent("", "  ")
+	if err := enc.Encode(health); err != nil {
+		return err
+	}
+	if !health.Up(modules...) {
+		return fmt.Errorf("server is not up: %s", health.Status)
+	}
+	return nil
+}
+
+func runVersion(ctx context.Context, e *env, args []string) error {
+	fs := e.flags("version", "")
+	if _, err := fs.parse(args); err != nil {
+		return err
+	}
+
+	version, err := e.client.GetVersion(ctx)
+	if err != nil {
+		return err
+	}
+	_, err = fmt.Fprintln(e.stdout, version)
+	return err
+}
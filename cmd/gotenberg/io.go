@@ -0,0 +1,122 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/nativebpm/gotenberg/v8"
+)
+
+// inputs are the files read by a command.
+type inputs struct {
+	files  []gotenberg.NamedFile
+	closer []io.Closer
+}
+
+// open opens the files named by args; "-" reads standard input as a file named stdinName.
+func (e *env) open(args []string, stdinName string) (*inputs, error) {
+	in := &inputs{}
+	stdin := false
+	for _, arg := range args {
+		if arg == "-" {
+			if stdin {
+				in.Close()
+				return nil, errors.New("standard input can only be read once")
+			}
+			stdin = true
+			in.files = append(in.files, gotenberg.NamedFile{Name: stdinName, Content: e.stdin})
+			continue
+		}
+
+		f, err := os.Open(arg)
+		if err != nil {
+			in.Close()
+			return nil, err
+		}
+		in.closer = append(in.closer, f)
+		in.files = append(in.files, gotenberg.NamedFile{Name: filepath.Base(arg), Content: f})
+	}
+	return in, nil
+}
+
+// send applies the request flags, sends the request with the given function and writes the response.
+func (e *env) send(r *gotenberg.Request, f *requestFlags, send func() (*gotenberg.Response, error)) error {
+	if err := f.apply(r); err != nil {
+		return err
+	}
+	resp, err := send()
+	if err != nil {
+		return err
+	}
+	return e.write(resp, f.output)
+}
+
+// Close closes the opened files.
+func (in *inputs) Close() error {
+	var errs []error
+	for _, c := range in.closer {
+		errs = append(errs, c.Close())
+	}
+	return errors.Join(errs...)
+}
+
+// write writes the body of a response to the output: a file, a directory where the file
+// is named after the response, or standard output for "-".
+func (e *env) write(resp *gotenberg.Response, output string) error {
+	defer resp.Body.Close()
+
+	if err := checkStatus(resp); err != nil {
+		return err
+	}
+	if output == "" || output == "-" {
+		_, err := io.Copy(e.stdout, resp.Body)
+		return err
+	}
+
+	if info, err := os.Stat(output); err == nil && info.IsDir() || strings.HasSuffix(output, string(filepath.Separator)) {
+		name := responseFilename(resp)
+		if name == "" {
+			return fmt.Errorf("the response has no filename to write it in %s", output)
+		}
+		if err := os.MkdirAll(output, 0o755); err != nil {
+			return err
+		}
+		output = filepath.Join(output, name)
+	}
+
+	f, err := os.Create(output)
+	if err != nil {
+		return err
+	}
+	if _, err := io.Copy(f, resp.Body); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
+
+// checkStatus reports a non-successful response as a *gotenberg.ResponseError.
+func checkStatus(resp *gotenberg.Response) error {
+	if resp.StatusCode >= 200 && resp.StatusCode < 300 {
+		return nil
+	}
+	b, _ := io.ReadAll(io.LimitReader(resp.Body, 4096))
+	return &gotenberg.ResponseError{
+		StatusCode:     resp.StatusCode,
+		GotenbergTrace: resp.GotenbergTrace,
+		Message:        strings.TrimSpace(string(b)),
+	}
+}
+
+// responseFilename returns the filename from the Content-Disposition header of the response.
+func responseFilename(resp *gotenberg.Response) string {
+	if _, params, err := mime.ParseMediaType(resp.Header.Get("Content-Disposition")); err == nil && params["filename"] != "" {
+		return filepath.Base(params["filename"])
+	}
+	return ""
+}
@@ -0,0 +1,115 @@
+package main
+
+import (
+	"context"
+	"path/filepath"
+
+	"github.com/nativebpm/gotenberg/v8"
+)
+
+func runOffice(ctx context.Context, e *env, args []string) error {
+	fs := e.flags("office", "[flags] file...")
+	var f requestFlags
+	var protected listFlag
+	f.register(fs)
+	fs.Var(&protected, "protected", "password of a file as `file=password`; may be repeated")
+	name := fs.String("name", "document.docx", "`filename` of the document read from standard input")
+	password := fs.String("password", "", "`password` opening the documents")
+	landscape := fs.Bool("landscape", false, "use the landscape orientation")
+	pageRanges := fs.String("page-ranges", "", "page `ranges` to export, e.g. '1-5, 8'")
+	merge := fs.Bool("merge", false, "merge the resulting PDFs in the given order")
+	pdfa := fs.String("pdfa", "", "convert to the PDF/A `format`, e.g. PDF/A-2b")
+	pdfua := fs.Bool("pdfua", false, "produce PDF/UA, accessible PDFs")
+	flatten := fs.Bool("flatten", false, "flatten the resulting PDFs")
+	singlePageSheets := fs.Bool("single-page-sheets", false, "put every sheet on exactly one page")
+	quality := fs.Int("quality", 0, "JPEG export `quality`, from 1 to 100")
+	noDetection := fs.Bool("no-format-detection", false, "upload the files without checking their formats")
+	var split splitFlags
+	split.register(fs)
+	args, err := fs.parse(args)
+	if err != nil {
+		return err
+	}
+	if len(args) == 0 && len(f.downloadFrom) == 0 {
+		return fs.usageError("expected documents to convert")
+	}
+
+	passwords := make(map[string]string)
+	pairs, err := protected.pairs()
+	if err != nil {
+		return fs.usageError("%v", err)
+	}
+	for _, p := range pairs {
+		passwords[filepath.Clean(p[0])] = p[1]
+	}
+
+	in, err := e.open(args, *name)
+	if err != nil {
+		return err
+	}
+	defer in.Close()
+
+	r := e.client.LibreOffice().Convert(ctx)
+	for i, file := range in.files {
+		if pw, ok := passwords[filepath.Clean(args[i])]; ok {
+			r.ProtectedFile(file.Name, file.Content, pw)
+		} else {
+			r.File(file.Name, file.Content)
+		}
+	}
+
+	if *password != "" {
+		r.Password(*password)
+	}
+	if fs.set["landscape"] {
+		r.Landscape(*landscape)
+	}
+	if *pageRanges != "" {
+		r.NativePageRanges(*pageRanges)
+	}
+	if fs.set["merge"] {
+		r.Merge(*merge)
+	}
+	if *pdfa != "" {
+		r.PDFA(*pdfa)
+	}
+	if fs.set["pdfua"] {
+		r.PDFUA(*pdfua)
+	}
+	if fs.set["flatten"] {
+		r.Flatten(*flatten)
+	}
+	if fs.set["single-page-sheets"] {
+		r.SinglePageSheets(*singlePageSheets)
+	}
+	if fs.set["quality"] {
+		r.Quality(*quality)
+	}
+	r.FormatDetection(!*noDetection)
+	if s, ok := split.split(); ok {
+		r.SplitBy(s)
+	}
+	return e.send(r.Request, &f, r.Send)
+}
+
+// splitFlags are the flags splitting PDFs.
+type splitFlags struct {
+	mode  string
+	span  string
+	unify bool
+}
+
+// register adds the flags to the flag set.
+func (f *splitFlags) register(fs *flagSet) {
+	fs.StringVar(&f.mode, "split-mode", "", "split `mode`: intervals or pages")
+	fs.StringVar(&f.span, "split-span", "", "page count in intervals mode, page `ranges` in pages mode")
+	fs.BoolVar(&f.unify, "split-unify", false, "in pages mode, put the extracted pages in a single PDF")
+}
+
+// split returns the split configuration, if a mode is set.
+func (f *splitFlags) split() (gotenberg.Split, bool) {
+	if f.mode == "" {
+		return gotenberg.Split{}, false
+	}
+	return gotenberg.Split{Mode: gotenberg.SplitMode(f.mode), Span: f.span, Unify: f.unify}, true
+}
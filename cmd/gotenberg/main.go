@@ -0,0 +1,161 @@
+// Command gotenberg sends documents to a Gotenberg server from the command line.
+//
+// Usage:
+//
+//	gotenberg [-url URL] [-timeout DURATION] <command> [flags] [files]
+//
+// Inputs are read from the given files, or from standard input when a file is "-".
+// Outputs are written to standard output, or to the file or directory given with -o.
+// The server URL defaults to $GOTENBERG_URL, then to http://localhost:3000.
+//
+// Run "gotenberg <command> -h" for the flags of a command.
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"os/signal"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/nativebpm/gotenberg/v8"
+)
+
+// command is a subcommand of the tool.
+type command struct {
+	name    string
+	summary string
+	run     func(ctx context.Context, e *env, args []string) error
+	// converts reports whether the command sends a conversion request, and so takes the
+	// -o and -trace flags.
+	converts bool
+}
+
+// commands lists the subcommands, in the order they are documented.
+// It is set by init, as the batch command runs the other ones.
+var commands []command
+
+func init() {
+	commands = []command{
+		{"html", "convert an HTML file to PDF with Chromium", runHTML, true},
+		{"url", "convert a web page to PDF with Chromium", runURL, true},
+		{"markdown", "convert Markdown files to PDF with Chromium", runMarkdown, true},
+		{"screenshot", "capture a web page, an HTML or a Markdown file as an image", runScreenshot, true},
+		{"office", "convert Office documents to PDF with LibreOffice", runOffice, true},
+		{"merge", "merge PDFs in the given order", runMerge, true},
+		{"split", "split PDFs by intervals or page ranges", runSplit, true},
+		{"flatten", "flatten the forms and annotations of PDFs", runFlatten, true},
+		{"rotate", "rotate the pages of PDFs", runRotate, true},
+		{"watermark", "add a watermark behind the content of PDFs", runWatermark, true},
+		{"stamp", "add a stamp on top of the content of PDFs", runStamp, true},
+		{"bookmarks", "read or write the bookmarks of PDFs", runBookmarks, true},
+		{"metadata", "read or write the metadata of PDFs", runMetadata, true},
+		{"health", "print the health of the server", runHealth, false},
+		{"version", "print the version of the server", runVersion, false},
+		{"watch", "convert the documents of a directory whenever they change", runWatch, false},
+		{"batch", "run the jobs of a manifest file", runBatch, false},
+	}
+}
+
+// findCommand returns the command with the given name, or nil.
+func findCommand(name string) *command {
+	for i := range commands {
+		if commands[i].name == name {
+			return &commands[i]
+		}
+	}
+	return nil
+}
+
+// errUsage reports invalid arguments, after the usage has been printed.
+var errUsage = errors.New("invalid usage")
+
+// env is the environment commands run in.
+type env struct {
+	client     *gotenberg.Client
+	httpClient *http.Client
+	urls       []string
+	stdin      io.Reader
+	stdout     io.Writer
+	stderr     io.Writer
+}
+
+func main() {
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	code := run(ctx, os.Args[1:], os.Stdin, os.Stdout, os.Stderr)
+	stop()
+	os.Exit(code)
+}
+
+// run executes the tool and returns its exit code.
+func run(ctx context.Context, args []string, stdin io.Reader, stdout, stderr io.Writer) int {
+	fs := flag.NewFlagSet("gotenberg", flag.ContinueOnError)
+	fs.SetOutput(stderr)
+	baseURL := fs.String("url", defaultURL(), "Gotenberg server `URL`; batch accepts several, comma-separated")
+	timeout := fs.Duration("timeout", 0, "timeout of each request, e.g. 30s (default none)")
+	fs.Usage = func() {
+		fmt.Fprintln(stderr, "Usage: gotenberg [flags] <command> [command flags] [files]")
+		fmt.Fprintln(stderr, "\nCommands:")
+		tw := tabwriter.NewWriter(stderr, 0, 0, 2, ' ', 0)
+		for _, c := range commands {
+			fmt.Fprintf(tw, "  %s\t%s\n", c.name, c.summary)
+		}
+		tw.Flush()
+		fmt.Fprintln(stderr, "\nFlags:")
+		fs.PrintDefaults()
+	}
+
+	if err := fs.Parse(args); err != nil {
+		if errors.Is(err, flag.ErrHelp) {
+			return 0
+		}
+		return 2
+	}
+	if fs.NArg() == 0 {
+		fs.Usage()
+		return 2
+	}
+
+	name := fs.Arg(0)
+	cmd := findCommand(name)
+	if cmd == nil {
+		fmt.Fprintf(stderr, "gotenberg: unknown command %q\n", name)
+		fs.Usage()
+		return 2
+	}
+
+	httpClient := &http.Client{Timeout: *timeout}
+	var urls []string
+	for _, u := range strings.Split(*baseURL, ",") {
+		urls = append(urls, strings.TrimSpace(u))
+	}
+	client, err := gotenberg.NewClient(httpClient, urls[0])
+	if err != nil {
+		fmt.Fprintf(stderr, "gotenberg: %v\n", err)
+		return 1
+	}
+
+	e := &env{client: client, httpClient: httpClient, urls: urls, stdin: stdin, stdout: stdout, stderr: stderr}
+	switch err := cmd.run(ctx, e, fs.Args()[1:]); {
+	case err == nil, errors.Is(err, flag.ErrHelp):
+		return 0
+	case errors.Is(err, errUsage):
+		return 2
+	default:
+		fmt.Fprintf(stderr, "gotenberg %s: %v\n", name, err)
+		return 1
+	}
+}
+
+// defaultURL returns the server URL from the environment, or the default local one.
+func defaultURL() string {
+	if u := os.Getenv("GOTENBERG_URL"); u != "" {
+		return u
+	}
+	return "http://localhost:3000"
+}
@@ -0,0 +1,374 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+
+	"github.com/nativebpm/gotenberg/v8"
+)
+
+// pdfFlags are the flags shared by PDF engines commands.
+type pdfFlags struct {
+	requestFlags
+	name  string
+	pdfa  string
+	pdfua bool
+}
+
+// register adds the flags to the flag set.
+func (f *pdfFlags) register(fs *flagSet) {
+	f.requestFlags.register(fs)
+	fs.StringVar(&f.name, "name", "input.pdf", "`filename` of the PDF read from standard input")
+	fs.StringVar(&f.pdfa, "pdfa", "", "convert to the PDF/A `format`, e.g. PDF/A-2b")
+	fs.BoolVar(&f.pdfua, "pdfua", false, "produce PDF/UA, accessible PDFs")
+}
+
+// apply sets the PDF/A and PDF/UA flags given to the command on the request.
+func (f *pdfFlags) apply(fs *flagSet, r *gotenberg.PDFEngines) {
+	if f.pdfa != "" {
+		r.PDFA(f.pdfa)
+	}
+	if fs.set["pdfua"] {
+		r.PDFUA(f.pdfua)
+	}
+}
+
+// pdfCommand parses the flags of a PDF engines command and opens its input PDFs,
+// of which there must be at least one.
+func pdfCommand(e *env, fs *flagSet, f *pdfFlags, args []string) (*inputs, error) {
+	f.register(fs)
+	args, err := fs.parse(args)
+	if err != nil {
+		return nil, err
+	}
+	if len(args) == 0 {
+		return nil, fs.usageError("expected PDF files")
+	}
+	return e.open(args, f.name)
+}
+
+// addFiles adds the input files to the request.
+func addFiles(r *gotenberg.PDFEngines, in *inputs) {
+	for _, f := range in.files {
+		r.File(f.Name, f.Content)
+	}
+}
+
+func runMerge(ctx context.Context, e *env, args []string) error {
+	fs := e.flags("merge", "[flags] file.pdf...")
+	var f pdfFlags
+	flatten := fs.Bool("flatten", false, "flatten the merged PDF")
+	in, err := pdfCommand(e, fs, &f, args)
+	if err != nil {
+		return err
+	}
+	defer in.Close()
+
+	r := e.client.PDFEngines().OrderedMerge(ctx, in.files...)
+	f.apply(fs, r)
+	if fs.set["flatten"] {
+		r.FlattenPDF(*flatten)
+	}
+	return e.send(r.Request, &f.requestFlags, r.Send)
+}
+
+func runSplit(ctx context.Context, e *env, args []string) error {
+	fs := e.flags("split", "[flags] file.pdf...")
+	var f pdfFlags
+	mode := fs.String("mode", "intervals", "split `mode`: intervals or pages")
+	span := fs.String("span", "", "page count in intervals mode, page `ranges` in pages mode")
+	unify := fs.Bool("unify", false, "in pages mode, put the extracted pages in a single PDF")
+	in, err := pdfCommand(e, fs, &f, args)
+	if err != nil {
+		return err
+	}
+	defer in.Close()
+	if *span == "" {
+		return fs.usageError("-span is required")
+	}
+
+	r := e.client.PDFEngines().Split(ctx).
+		SplitBy(gotenberg.Split{Mode: gotenberg.SplitMode(*mode), Span: *span, Unify: *unify})
+	addFiles(r, in)
+	f.apply(fs, r)
+	return e.send(r.Request, &f.requestFlags, r.Send)
+}
+
+func runFlatten(ctx context.Context, e *env, args []string) error {
+	fs := e.flags("flatten", "[flags] file.pdf...")
+	var f pdfFlags
+	in, err := pdfCommand(e, fs, &f, args)
+	if err != nil {
+		return err
+	}
+	defer in.Close()
+
+	r := e.client.PDFEngines().Flatten(ctx)
+	addFiles(r, in)
+	f.apply(fs, r)
+	return e.send(r.Request, &f.requestFlags, r.Send)
+}
+
+func runRotate(ctx context.Context, e *env, args []string) error {
+	fs := e.flags("rotate", "[flags] file.pdf...")
+	var f pdfFlags
+	angle := fs.Int("angle", 90, "rotation `angle`: 90, 180 or 270")
+	pages := fs.String("pages", "", "page `ranges` to rotate, e.g. '1-5, 8' (default all)")
+	in, err := pdfCommand(e, fs, &f, args)
+	if err != nil {
+		return err
+	}
+	defer in.Close()
+
+	r := e.client.PDFEngines().Rotate(ctx).RotateAngle(*angle)
+	if *pages != "" {
+		r.RotatePages(*pages)
+	}
+	addFiles(r, in)
+	f.apply(fs, r)
+	return e.send(r.Request, &f.requestFlags, r.Send)
+}
+
+// overlayFlags are the flags of watermarks and stamps.
+type overlayFlags struct {
+	source     string
+	expression string
+	file       string
+	pages      string
+	opacity    float64
+	rotation   float64
+	scale      float64
+	position   string
+	offsetX    float64
+	offsetY    float64
+	font       string
+	fontSize   int
+	color      string
+	fs         *flagSet
+}
+
+// register adds the flags to the flag set.
+func (f *overlayFlags) register(fs *flagSet) {
+	f.fs = fs
+	fs.StringVar(&f.source, "source", "text", "`kind` of source: text, image or pdf")
+	fs.StringVar(&f.expression, "text", "", "`text` of a text source")
+	fs.StringVar(&f.file, "file", "", "image or PDF `file` of an image or pdf source")
+	fs.StringVar(&f.pages, "pages", "", "page `ranges` to apply it to, e.g. '1-5, 8' (default all)")
+	fs.Float64Var(&f.opacity, "opacity", 0, "`opacity`, from 0 to 1")
+	fs.Float64Var(&f.rotation, "rotation", 0, "rotation `angle` in degrees")
+	fs.Float64Var(&f.scale, "scale", 0, "scale `factor` relative to the page, from 0 to 1")
+	fs.StringVar(&f.position, "position", "", "`anchor` on the page: tl, tc, tr, l, c, r, bl, bc or br")
+	fs.Float64Var(&f.offsetX, "offset-x", 0, "horizontal `offset` from the anchor, in points")
+	fs.Float64Var(&f.offsetY, "offset-y", 0, "vertical `offset` from the anchor, in points")
+	fs.StringVar(&f.font, "font", "", "font `name` of a text source")
+	fs.IntVar(&f.fontSize, "font-size", 0, "font `size` of a text source, in points")
+	fs.StringVar(&f.color, "color", "", "fill `color` of a text source, e.g. #808080")
+}
+
+// options returns the watermark options, opening the source file if any.
+func (f *overlayFlags) options(e *env) (gotenberg.WatermarkOptions, *inputs, error) {
+	o := gotenberg.WatermarkOptions{
+		Source:     gotenberg.WatermarkSource(f.source),
+		Expression: f.expression,
+		Pages:      f.pages,
+		Rotation:   f.rotation,
+		Position:   gotenberg.Position(f.position),
+		OffsetX:    f.offsetX,
+		OffsetY:    f.offsetY,
+		Font:       f.font,
+		FontSize:   f.fontSize,
+		Color:      f.color,
+	}
+	if f.fs.set["opacity"] {
+		o.Opacity = &f.opacity
+	}
+	if f.fs.set["scale"] {
+		o.Scale = &f.scale
+	}
+	if f.file == "" {
+		return o, &inputs{}, nil
+	}
+
+	in, err := e.open([]string{f.file}, "source")
+	if err != nil {
+		return o, nil, err
+	}
+	o.File = &in.files[0]
+	return o, in, nil
+}
+
+// runOverlay runs the watermark or stamp command.
+func runOverlay(ctx context.Context, e *env, name string, args []string) error {
+	fs := e.flags(name, "[flags] file.pdf...")
+	var f pdfFlags
+	var overlay overlayFlags
+	overlay.register(fs)
+	in, err := pdfCommand(e, fs, &f, args)
+	if err != nil {
+		return err
+	}
+	defer in.Close()
+
+	o, source, err := overlay.options(e)
+	if err != nil {
+		return err
+	}
+	defer source.Close()
+
+	r := e.client.PDFEngines()
+	if name == "stamp" {
+		r.Stamp(ctx).StampOptions(gotenberg.StampOptions(o))
+	} else {
+		r.Watermark(ctx).WatermarkOptions(o)
+	}
+	addFiles(r, in)
+	f.apply(fs, r)
+	return e.send(r.Request, &f.requestFlags, r.Send)
+}
+
+func runWatermark(ctx context.Context, e *env, args []string) error {
+	return runOverlay(ctx, e, "watermark", args)
+}
+
+func runStamp(ctx context.Context, e *env, args []string) error {
+	return runOverlay(ctx, e, "stamp", args)
+}
+
+// subcommand returns the action of a command taking a read or write action as first argument.
+func subcommand(fs *flagSet, args []string) (string, []string, error) {
+	if len(args) > 0 && (args[0] == "-h" || args[0] == "-help" || args[0] == "--help") {
+		fs.Usage()
+		return "", nil, flag.ErrHelp
+	}
+	if len(args) == 0 || args[0] != "read" && args[0] != "write" {
+		return "", nil, fs.usageError("expected read or write")
+	}
+	return args[0], args[1:], nil
+}
+
+// writeJSON writes a JSON response to the output, indented.
+func (e *env) writeJSON(resp *gotenberg.Response, output string) error {
+	defer resp.Body.Close()
+
+	if err := checkStatus(resp); err != nil {
+		return err
+	}
+	var raw json.RawMessage
+	if err := json.NewDecoder(resp.Body).Decode(&raw); err != nil {
+		return err
+	}
+	var b bytes.Buffer
+	if err := json.Indent(&b, raw, "", "  "); err != nil {
+		return err
+	}
+	b.WriteByte('\n')
+
+	if output == "" || output == "-" {
+		_, err := b.WriteTo(e.stdout)
+		return err
+	}
+	return os.WriteFile(output, b.Bytes(), 0o644)
+}
+
+func runBookmarks(ctx context.Context, e *env, args []string) error {
+	fs := e.flags("bookmarks", "{read file.pdf... | write -bookmarks bookmarks.json file.pdf}")
+	action, args, err := subcommand(fs, args)
+	if err != nil {
+		return err
+	}
+
+	var f pdfFlags
+	bookmarksFile := fs.String("bookmarks", "", "with write, JSON `file` of the bookmarks to write")
+	in, err := pdfCommand(e, fs, &f, args)
+	if err != nil {
+		return err
+	}
+	defer in.Close()
+
+	r := e.client.PDFEngines()
+	if action == "read" {
+		r.BookmarksRead(ctx)
+		addFiles(r, in)
+		if err := f.requestFlags.apply(r.Request); err != nil {
+			return err
+		}
+		resp, err := r.Send()
+		if err != nil {
+			return err
+		}
+		return e.writeJSON(resp, f.output)
+	}
+
+	if *bookmarksFile == "" {
+		return fs.usageError("-bookmarks is required")
+	}
+	b, err := os.ReadFile(*bookmarksFile)
+	if err != nil {
+		return err
+	}
+	var bookmarks []gotenberg.Bookmark
+	if err := json.Unmarshal(b, &bookmarks); err != nil {
+		return fmt.Errorf("%s: %w", *bookmarksFile, err)
+	}
+	if err := gotenberg.ValidateBookmarks(bookmarks, 0); err != nil {
+		return fmt.Errorf("%s: %w", *bookmarksFile, err)
+	}
+
+	r.BookmarksWrite(ctx).Bookmarks(string(b))
+	addFiles(r, in)
+	f.apply(fs, r)
+	return e.send(r.Request, &f.requestFlags, r.Send)
+}
+
+func runMetadata(ctx context.Context, e *env, args []string) error {
+	fs := e.flags("metadata", "{read file.pdf... | write [-metadata key=value]... [-from metadata.json] file.pdf...}")
+	action, args, err := subcommand(fs, args)
+	if err != nil {
+		return err
+	}
+
+	var f pdfFlags
+	from := fs.String("from", "", "with write, JSON `file` of the metadata to write")
+	in, err := pdfCommand(e, fs, &f, args)
+	if err != nil {
+		return err
+	}
+	defer in.Close()
+
+	r := e.client.PDFEngines()
+	if action == "read" {
+		r.MetadataRead(ctx)
+		addFiles(r, in)
+		if err := f.requestFlags.apply(r.Request); err != nil {
+			return err
+		}
+		resp, err := r.Send()
+		if err != nil {
+			return err
+		}
+		return e.writeJSON(resp, f.output)
+	}
+
+	var m gotenberg.PDFMetadata
+	if *from != "" {
+		b, err := os.ReadFile(*from)
+		if err != nil {
+			return err
+		}
+		if err := json.Unmarshal(b, &m); err != nil {
+			return fmt.Errorf("%s: %w", *from, err)
+		}
+	}
+	if *from == "" && len(f.metadata) == 0 {
+		return fs.usageError("nothing to write: use -metadata or -from")
+	}
+
+	r.MetadataWrite(ctx).PDFMetadata(m)
+	addFiles(r, in)
+	f.apply(fs, r)
+	return e.send(r.Request, &f.requestFlags, r.Send)
+}
@@ -0,0 +1,340 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/nativebpm/gotenberg/v8"
+)
+
+// assetFormats lists the extensions of files that are referenced by documents rather than
+// converted themselves, even though LibreOffice accepts some of them.
+var assetFormats = map[string]bool{
+	"bmp": true, "csv": true, "emf": true, "eps": true, "gif": true, "jpeg": true, "jpg": true,
+	"pbm": true, "pcx": true, "pdf": true, "pgm": true, "png": true, "ppm": true, "psd": true,
+	"svg": true, "tga": true, "tif": true, "tiff": true, "txt": true, "wmf": true, "xbm": true,
+	"xhtml": true, "xml": true, "xpm": true,
+}
+
+// references matches the local files an HTML or Markdown source may refer to: src and href
+// attributes, CSS url() functions, Markdown images and links, and toHTML template calls.
+var references = regexp.MustCompile(`(?:src|href)\s*=\s*["']([^"']+)["']|url\(\s*["']?([^"')]+)["']?\s*\)|\]\(\s*<?([^)\s>]+)>?(?:\s+"[^"]*")?\s*\)|toHTML\s+"([^"]+)"`)
+
+// watchFlags are the flags of the watch command.
+type watchFlags struct {
+	interval   time.Duration
+	debounce   time.Duration
+	screenshot bool
+	format     string
+	once       bool
+}
+
+// watcher converts the documents of a directory whenever they or their assets change.
+type watcher struct {
+	e     *env
+	dir   string
+	flags watchFlags
+
+	// files holds the modification time and size of the files of the directory.
+	files map[string]fileState
+	// assets holds the files referenced by each source, as of its last conversion.
+	assets map[string][]string
+	// outputs holds the files written by the watcher, whose changes are ignored.
+	outputs map[string]bool
+}
+
+// fileState identifies a version of a file.
+type fileState struct {
+	modTime time.Time
+	size    int64
+}
+
+func runWatch(ctx context.Context, e *env, args []string) error {
+	fs := e.flags("watch", "[flags] [directory]")
+	var f watchFlags
+	fs.DurationVar(&f.interval, "interval", 500*time.Millisecond, "`duration` between two scans of the directory")
+	fs.DurationVar(&f.debounce, "debounce", 300*time.Millisecond, "quiet `duration` awaited after a change before converting")
+	fs.BoolVar(&f.screenshot, "screenshot", false, "capture HTML and Markdown sources as images instead of PDFs")
+	fs.StringVar(&f.format, "format", "png", "with -screenshot, image `format`: png, jpeg or webp")
+	fs.BoolVar(&f.once, "once", false, "convert the outdated sources and exit")
+	args, err := fs.parse(args)
+	if err != nil {
+		return err
+	}
+	dir := "."
+	switch len(args) {
+	case 0:
+	case 1:
+		dir = args[0]
+	default:
+		return fs.usageError("expected a single directory")
+	}
+	if f.interval <= 0 {
+		return fs.usageError("-interval must be positive")
+	}
+
+	w := &watcher{e: e, dir: dir, flags: f, assets: make(map[string][]string), outputs: make(map[string]bool)}
+	if w.files, err = w.scan(); err != nil {
+		return err
+	}
+
+	failed := 0
+	for _, src := range w.sources() {
+		if w.outdated(src) && !w.convert(ctx, src) {
+			failed++
+		}
+	}
+	if f.once {
+		if failed > 0 {
+			return fmt.Errorf("%d conversions failed", failed)
+		}
+		return nil
+	}
+
+	fmt.Fprintf(e.stderr, "watching %s\n", dir)
+	return w.watch(ctx)
+}
+
+// watch polls the directory until the context is done, converting the sources affected by
+// changes once no other change has been seen for the debounce duration.
+func (w *watcher) watch(ctx context.Context) error {
+	ticker := time.NewTicker(w.flags.interval)
+	defer ticker.Stop()
+
+	pending := make(map[string]bool)
+	var last time.Time
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-ticker.C:
+		}
+
+		files, err := w.scan()
+		if err != nil {
+			fmt.Fprintf(w.e.stderr, "%s: %v\n", w.dir, err)
+			continue
+		}
+		for _, path := range w.changes(files) {
+			pending[path] = true
+			last = time.Now()
+		}
+		w.files = files
+
+		if len(pending) == 0 || time.Since(last) < w.flags.debounce {
+			continue
+		}
+		for _, src := range w.sources() {
+			if pending[src] || slices.ContainsFunc(w.assets[src], func(a string) bool { return pending[a] }) {
+				w.convert(ctx, src)
+			}
+		}
+		clear(pending)
+	}
+}
+
+// scan returns the state of the files of the directory, skipping hidden files and directories.
+func (w *watcher) scan() (map[string]fileState, error) {
+	files := make(map[string]fileState)
+	err := filepath.WalkDir(w.dir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if path != w.dir && (strings.HasPrefix(d.Name(), ".") || strings.HasPrefix(d.Name(), "~$")) {
+			if d.IsDir() {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if !d.Type().IsRegular() {
+			return nil
+		}
+		info, err := d.Info()
+		if err != nil {
+			if errors.Is(err, fs.ErrNotExist) {
+				return nil
+			}
+			return err
+		}
+		files[path] = fileState{info.ModTime(), info.Size()}
+		return nil
+	})
+	return files, err
+}
+
+// changes returns the files added, modified or removed since the last scan, except outputs.
+func (w *watcher) changes(files map[string]fileState) []string {
+	var changed []string
+	for path, s := range files {
+		if old, ok := w.files[path]; (!ok || old != s) && !w.outputs[path] {
+			changed = append(changed, path)
+		}
+	}
+	for path := range w.files {
+		if _, ok := files[path]; !ok && !w.outputs[path] {
+			changed = append(changed, path)
+		}
+	}
+	return changed
+}
+
+// sources returns the files to convert, sorted.
+func (w *watcher) sources() []string {
+	var sources []string
+	for path := range w.files {
+		if w.kind(path) != "" && !w.outputs[path] {
+			sources = append(sources, path)
+		}
+	}
+	slices.Sort(sources)
+	return sources
+}
+
+// kind returns how a file is converted: "html", "markdown" or "office", or "" if it is not a source.
+func (w *watcher) kind(path string) string {
+	ext := strings.ToLower(strings.TrimPrefix(filepath.Ext(path), "."))
+	switch {
+	case ext == "html" || ext == "htm":
+		return "html"
+	case ext == "md" || ext == "markdown":
+		return "markdown"
+	case gotenberg.LibreOfficeFormats[ext] && !assetFormats[ext]:
+		return "office"
+	default:
+		return ""
+	}
+}
+
+// output returns the file a source is converted to, next to it.
+func (w *watcher) output(src string) string {
+	ext := ".pdf"
+	if w.flags.screenshot && w.kind(src) != "office" {
+		ext = "." + w.flags.format
+	}
+	return strings.TrimSuffix(src, filepath.Ext(src)) + ext
+}
+
+// outdated reports whether the output of a source is missing or older than the source or its assets.
+func (w *watcher) outdated(src string) bool {
+	out, ok := w.files[w.output(src)]
+	if !ok {
+		return true
+	}
+	data, err := os.ReadFile(src)
+	if err != nil {
+		return true
+	}
+	for _, path := range append(w.references(src, data), src) {
+		if s, ok := w.files[path]; ok && s.modTime.After(out.modTime) {
+			return true
+		}
+	}
+	return false
+}
+
+// references returns the existing local files the source refers to.
+func (w *watcher) references(src string, data []byte) []string {
+	if w.kind(src) == "office" {
+		return nil
+	}
+	var paths []string
+	for _, m := range references.FindAllSubmatch(data, -1) {
+		var ref string
+		for _, g := range m[1:] {
+			if len(g) > 0 {
+				ref = string(g)
+			}
+		}
+		ref, _, _ = strings.Cut(ref, "#")
+		ref, _, _ = strings.Cut(ref, "?")
+		if ref == "" || strings.Contains(ref, ":") || filepath.IsAbs(ref) {
+			continue
+		}
+		path := filepath.Join(filepath.Dir(src), filepath.FromSlash(ref))
+		if _, ok := w.files[path]; ok && path != src && !slices.Contains(paths, path) {
+			paths = append(paths, path)
+		}
+	}
+	return paths
+}
+
+// convert converts a source and reports the outcome; it returns false if the conversion failed.
+func (w *watcher) convert(ctx context.Context, src string) bool {
+	start := time.Now()
+	out := w.output(src)
+	if err := w.send(ctx, src, out); err != nil {
+		fmt.Fprintf(w.e.stderr, "%s: %v\n", src, err)
+		return false
+	}
+	w.outputs[out] = true
+	if info, err := os.Stat(out); err == nil {
+		w.files[out] = fileState{info.ModTime(), info.Size()}
+	}
+	fmt.Fprintf(w.e.stderr, "%s -> %s (%s)\n", src, out, time.Since(start).Round(time.Millisecond))
+	return true
+}
+
+// send converts a source with the builder matching its kind and writes the result.
+func (w *watcher) send(ctx context.Context, src, out string) error {
+	data, err := os.ReadFile(src)
+	if err != nil {
+		return err
+	}
+	w.assets[src] = w.references(src, data)
+	name := filepath.Base(src)
+
+	if w.kind(src) == "office" {
+		r := w.e.client.LibreOffice().Convert(ctx).File(name, bytes.NewReader(data))
+		resp, err := r.Send()
+		if err != nil {
+			return err
+		}
+		return w.e.write(resp, out)
+	}
+
+	r := w.e.client.Chromium()
+	page := bytes.NewReader(data)
+	switch {
+	case w.kind(src) == "markdown" && w.flags.screenshot:
+		r.ScreenshotMarkdown(ctx, markdownWrapper([]gotenberg.NamedFile{{Name: name}})).File(name, page)
+	case w.kind(src) == "markdown":
+		r.ConvertMarkdown(ctx, markdownWrapper([]gotenberg.NamedFile{{Name: name}})).File(name, page)
+	case w.flags.screenshot:
+		r.ScreenshotHTML(ctx, page)
+	default:
+		r.ConvertHTML(ctx, page)
+	}
+	if w.flags.screenshot {
+		r.ScreenshotFormat(w.flags.format)
+	}
+
+	in, err := w.e.open(w.assets[src], "")
+	if err != nil {
+		return err
+	}
+	defer in.Close()
+	for i, f := range in.files {
+		// Assets keep their path relative to the source, so that references such as
+		// img/logo.png still resolve.
+		name, err := filepath.Rel(filepath.Dir(src), w.assets[src][i])
+		if err != nil {
+			return err
+		}
+		r.File(filepath.ToSlash(name), f.Content)
+	}
+
+	resp, err := r.Send()
+	if err != nil {
+		return err
+	}
+	return w.e.write(resp, out)
+}
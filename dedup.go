@@ -0,0 +1,309 @@
+package gotenberg
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"os"
+	"sync"
+)
+
+// Deduplicate makes concurrent identical requests share one request to Gotenberg, identified by
+// the key of the result cache. The response body is fanned out to all callers, each reading it at
+// its own pace: it is buffered in memory up to maxMemory bytes, 8 MiB if zero, then spilled to a
+// temporary file removed once all bodies are closed.
+//
+// The shared request is sent on a context detached from the callers. A caller whose context is done
+// stops waiting for the response, or reading its body, on its own; the request is cancelled once no
+// caller waits for it or reads its body. When it times out, the callers that joined it and whose
+// context is not done send their own. Requests the cache would bypass, such as URL conversions,
+// are always sent.
+func (c *Client) Deduplicate(maxMemory int64) *Client {
+	if maxMemory <= 0 {
+		maxMemory = 8 << 20
+	}
+	c.flights = &flightGroup{maxMemory: maxMemory, flights: make(map[string]*flight)}
+	return c
+}
+
+// flightGroup tracks the requests in flight by key.
+type flightGroup struct {
+	maxMemory int64
+	mu        sync.Mutex
+	flights   map[string]*flight
+}
+
+// flight is a request in flight, shared by the callers holding a reference to it.
+type flight struct {
+	group *flightGroup
+	key   string
+	// cancel cancels the context of the request.
+	cancel context.CancelFunc
+	// ready is closed once the response or the error is known.
+	ready chan struct{}
+	resp  *http.Response
+	err   error
+	// body is the shared body of the response; group.mu must be held.
+	body *sharedBody
+	// refs is the number of callers waiting for the response or reading its body; group.mu must be held.
+	refs int
+}
+
+// do sends a request with send unless an identical one is in flight, and returns the response with
+// a body of its own. send uses a context detached from ctx, cancelled by cancel once the request is
+// not needed anymore. shared reports whether the request was sent by another caller. When the
+// request of another caller times out, it is sent again, retry being called first.
+func (g *flightGroup) do(ctx context.Context, key string, send func() (*http.Response, error), cancel context.CancelFunc, retry func(attempt int, err error)) (resp *http.Response, shared bool, err error) {
+	sent := false
+	defer func() {
+		if !sent {
+			cancel()
+		}
+	}()
+
+	for attempt := 1; ; attempt++ {
+		g.mu.Lock()
+		f, ok := g.flights[key]
+		if !ok {
+			f = &flight{group: g, key: key, cancel: cancel, ready: make(chan struct{})}
+			g.flights[key] = f
+			sent = true
+			go f.send(send)
+		}
+		f.refs++
+		g.mu.Unlock()
+		shared = ok
+
+		select {
+		case <-f.ready:
+		case <-ctx.Done():
+			f.release()
+			return nil, shared, ctx.Err()
+		}
+		if f.err == nil {
+			return f.response(ctx), shared, nil
+		}
+		f.release()
+		if !shared || !errors.Is(f.err, context.DeadlineExceeded) || ctx.Err() != nil {
+			return nil, shared, f.err
+		}
+		retry(attempt, f.err)
+	}
+}
+
+// send sends the request of the flight and makes its response available to the callers.
+func (f *flight) send(send func() (*http.Response, error)) {
+	defer close(f.ready)
+
+	g := f.group
+	resp, err := send()
+	if err != nil {
+		g.forget(f)
+		f.err = err
+		return
+	}
+	body := &sharedBody{src: resp.Body, maxMemory: g.maxMemory}
+	body.cond = sync.NewCond(&body.mu)
+
+	g.mu.Lock()
+	f.resp, f.body = resp, body
+	abandoned := f.refs == 0
+	g.mu.Unlock()
+	if abandoned {
+		// Every caller left before the response.
+		resp.Body.Close()
+		return
+	}
+	go func() {
+		body.fill()
+		// Once the body is read entirely, later requests are sent again.
+		g.forget(f)
+	}()
+}
+
+// forget removes a flight from the group, so that no caller joins it anymore.
+func (g *flightGroup) forget(f *flight) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	if g.flights[f.key] == f {
+		delete(g.flights, f.key)
+	}
+}
+
+// response returns a copy of the response of the flight, with a reader of the shared body
+// bounded by the context of the caller.
+func (f *flight) response(ctx context.Context) *http.Response {
+	resp := *f.resp
+	resp.Header = f.resp.Header.Clone()
+	r := &sharedReader{body: f.body, ctx: ctx, release: f.release}
+	r.stop = context.AfterFunc(ctx, f.body.wake)
+	resp.Body = r
+	return &resp
+}
+
+// release drops a reference to the flight, and cancels its request and closes its body once
+// unreferenced.
+func (f *flight) release() {
+	g := f.group
+	g.mu.Lock()
+	f.refs--
+	last := f.refs == 0
+	if last && g.flights[f.key] == f {
+		delete(g.flights, f.key)
+	}
+	body := f.body
+	g.mu.Unlock()
+
+	if last {
+		f.cancel()
+		if body != nil {
+			body.close()
+		}
+	}
+}
+
+// sharedBody buffers a response body read by several callers, in memory then in a temporary file.
+type sharedBody struct {
+	src       io.ReadCloser
+	maxMemory int64
+
+	mu   sync.Mutex
+	cond *sync.Cond
+	mem  []byte
+	file *os.File
+	size int64
+	// err is the error that ended the reading of the source, io.EOF once read entirely.
+	err error
+	// closed reports whether all the readers are closed.
+	closed bool
+}
+
+// fill reads the source until its end, an error, or the closing of all the readers.
+func (b *sharedBody) fill() {
+	buf := make([]byte, 32<<10)
+	for {
+		n, err := b.src.Read(buf)
+		if n > 0 {
+			if werr := b.write(buf[:n]); werr != nil {
+				err = werr
+			}
+		}
+		if err == nil {
+			continue
+		}
+
+		b.src.Close()
+		b.mu.Lock()
+		defer b.mu.Unlock()
+		b.err = err
+		b.cond.Broadcast()
+		if b.closed {
+			b.removeFile()
+		}
+		return
+	}
+}
+
+// write appends data read from the source, spilling the buffer to a temporary file beyond maxMemory.
+func (b *sharedBody) write(p []byte) error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.file == nil && b.size+int64(len(p)) > b.maxMemory {
+		f, err := os.CreateTemp("", "gotenberg-*")
+		if err != nil {
+			return err
+		}
+		b.file = f
+		if _, err := f.Write(b.mem); err != nil {
+			return err
+		}
+		b.mem = nil
+	}
+	if b.file != nil {
+		if _, err := b.file.Write(p); err != nil {
+			return err
+		}
+	} else {
+		b.mem = append(b.mem, p...)
+	}
+	b.size += int64(len(p))
+	b.cond.Broadcast()
+	return nil
+}
+
+// close stops the reading of the source, and removes the temporary file once it has stopped.
+func (b *sharedBody) close() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.closed = true
+	if b.err == nil {
+		// fill fails and removes the file.
+		b.src.Close()
+		return
+	}
+	b.removeFile()
+}
+
+// wake wakes the readers up, for them to check their context.
+func (b *sharedBody) wake() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.cond.Broadcast()
+}
+
+// removeFile removes the temporary file, if any; b.mu must be held.
+func (b *sharedBody) removeFile() {
+	if b.file != nil {
+		b.file.Close()
+		os.Remove(b.file.Name())
+	}
+}
+
+// sharedReader reads a shared body from its start, until the context of its caller is done.
+type sharedReader struct {
+	body    *sharedBody
+	off     int64
+	ctx     context.Context
+	stop    func() bool
+	release func()
+	once    sync.Once
+}
+
+// Read implements the io.Reader interface, waiting for the data not read from the source yet.
+func (r *sharedReader) Read(p []byte) (int, error) {
+	b := r.body
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	for r.off >= b.size && b.err == nil && r.ctx.Err() == nil {
+		b.cond.Wait()
+	}
+	if err := r.ctx.Err(); err != nil {
+		return 0, err
+	}
+	if r.off >= b.size {
+		return 0, b.err
+	}
+
+	var n int
+	var err error
+	if b.file != nil {
+		n, err = b.file.ReadAt(p[:min(int64(len(p)), b.size-r.off)], r.off)
+	} else {
+		n = copy(p, b.mem[r.off:])
+	}
+	r.off += int64(n)
+	if err == io.EOF {
+		err = nil
+	}
+	return n, err
+}
+
+// Close implements the io.Closer interface.
+func (r *sharedReader) Close() error {
+	r.once.Do(func() {
+		r.stop()
+		r.release()
+	})
+	return nil
+}
@@ -0,0 +1,249 @@
+package gotenberg
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"os"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// eventually fails the test unless cond becomes true within a second.
+func eventually(t *testing.T, what string, cond func() bool) {
+	t.Helper()
+	for deadline := time.Now().Add(time.Second); !cond(); time.Sleep(time.Millisecond) {
+		if time.Now().After(deadline) {
+			t.Fatalf("timed out waiting for %s", what)
+		}
+	}
+}
+
+// caller is a caller of a flight group, with a send of its own.
+type caller struct {
+	ctx     context.Context
+	leave   context.CancelFunc
+	sendCtx context.Context
+	cancel  context.CancelFunc
+	// aborted reports whether the request was sent and cancelled before its response.
+	aborted atomic.Bool
+	retries int
+	resp    *http.Response
+	shared  bool
+	err     error
+	done    chan struct{}
+}
+
+func TestFlightGroup(t *testing.T) {
+	const body = "%PDF-1.7 shared"
+	tests := []struct {
+		name string
+		// err is the error of the request of the first caller.
+		err                        error
+		leaderLeaves, joinerLeaves bool
+		wantLeaderErr              error
+		wantJoinerErr              error
+		wantJoinerShared           bool
+		wantRetries                int
+		wantAborted                bool
+	}{
+		{name: "shared", wantJoinerShared: true},
+		{name: "leader leaves", leaderLeaves: true, wantLeaderErr: context.Canceled, wantJoinerShared: true},
+		{name: "joiner leaves", joinerLeaves: true, wantJoinerErr: context.Canceled},
+		{name: "every caller leaves", leaderLeaves: true, joinerLeaves: true, wantLeaderErr: context.Canceled, wantJoinerErr: context.Canceled, wantAborted: true},
+		{name: "timeout", err: context.DeadlineExceeded, wantLeaderErr: context.DeadlineExceeded, wantRetries: 1},
+		{name: "failure", err: errors.ErrUnsupported, wantLeaderErr: errors.ErrUnsupported, wantJoinerErr: errors.ErrUnsupported},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := &flightGroup{maxMemory: 1 << 20, flights: make(map[string]*flight)}
+			proceed := make(chan struct{})
+			var mu sync.Mutex
+			sends := 0
+
+			start := func() *caller {
+				c := &caller{done: make(chan struct{})}
+				c.ctx, c.leave = context.WithCancel(context.Background())
+				c.sendCtx, c.cancel = context.WithCancel(context.Background())
+				send := func() (*http.Response, error) {
+					mu.Lock()
+					sends++
+					first := sends == 1
+					mu.Unlock()
+					select {
+					case <-proceed:
+					case <-c.sendCtx.Done():
+					}
+					if err := c.sendCtx.Err(); err != nil {
+						c.aborted.Store(true)
+						return nil, err
+					}
+					if first && tt.err != nil {
+						return nil, tt.err
+					}
+					return &http.Response{StatusCode: http.StatusOK, Header: http.Header{}, Body: io.NopCloser(strings.NewReader(body))}, nil
+				}
+				go func() {
+					defer close(c.done)
+					retry := func(int, error) { c.retries++ }
+					c.resp, c.shared, c.err = g.do(c.ctx, "key", send, c.cancel, retry)
+				}()
+				return c
+			}
+			refs := func(n int) func() bool {
+				return func() bool {
+					g.mu.Lock()
+					defer g.mu.Unlock()
+					f := g.flights["key"]
+					return f != nil && f.refs == n
+				}
+			}
+
+			leader := start()
+			eventually(t, "the leader", refs(1))
+			joiner := start()
+			eventually(t, "the joiner", refs(2))
+			if tt.leaderLeaves {
+				leader.leave()
+				<-leader.done
+			}
+			if tt.joinerLeaves {
+				joiner.leave()
+				<-joiner.done
+			}
+			close(proceed)
+			<-leader.done
+			<-joiner.done
+
+			for _, c := range []struct {
+				name    string
+				c       *caller
+				wantErr error
+				shared  bool
+			}{
+				{"leader", leader, tt.wantLeaderErr, false},
+				{"joiner", joiner, tt.wantJoinerErr, tt.wantJoinerShared},
+			} {
+				if !errors.Is(c.c.err, c.wantErr) || (c.wantErr == nil) != (c.c.err == nil) {
+					t.Errorf("%s: error = %v, want %v", c.name, c.c.err, c.wantErr)
+				}
+				if c.c.err != nil {
+					continue
+				}
+				if c.c.shared != c.shared {
+					t.Errorf("%s: shared = %v, want %v", c.name, c.c.shared, c.shared)
+				}
+				got, err := io.ReadAll(c.c.resp.Body)
+				c.c.resp.Body.Close()
+				if err != nil || string(got) != body {
+					t.Errorf("%s: body = %q, %v, want %q", c.name, got, err, body)
+				}
+			}
+			if joiner.retries != tt.wantRetries {
+				t.Errorf("retries = %d, want %d", joiner.retries, tt.wantRetries)
+			}
+			if tt.wantAborted {
+				eventually(t, "the cancellation of the request", leader.aborted.Load)
+			} else if leader.aborted.Load() {
+				t.Error("request cancelled before its response")
+			}
+			// Once every body is closed, the requests are released and forgotten.
+			eventually(t, "the release of the requests", func() bool {
+				g.mu.Lock()
+				defer g.mu.Unlock()
+				return len(g.flights) == 0 && leader.sendCtx.Err() != nil && joiner.sendCtx.Err() != nil
+			})
+		})
+	}
+}
+
+func TestSharedBody(t *testing.T) {
+	tests := []struct {
+		name      string
+		size      int
+		maxMemory int64
+		// closeEarly closes the readers before the body is read entirely.
+		closeEarly bool
+		wantFile   bool
+	}{
+		{name: "in memory", size: 16, maxMemory: 32},
+		{name: "spilled", size: 64, maxMemory: 32, wantFile: true},
+		{name: "spilled then closed", size: 64, maxMemory: 32, closeEarly: true, wantFile: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			data := strings.Repeat("x", tt.size)
+			src, w := io.Pipe()
+			b := &sharedBody{src: src, maxMemory: tt.maxMemory}
+			b.cond = sync.NewCond(&b.mu)
+			filled := make(chan struct{})
+			go func() {
+				b.fill()
+				close(filled)
+			}()
+
+			var wg sync.WaitGroup
+			var refs sync.WaitGroup
+			refs.Add(2)
+			go func() {
+				refs.Wait()
+				b.close()
+			}()
+			readers := make([]*sharedReader, 2)
+			for i := range readers {
+				readers[i] = &sharedReader{body: b, ctx: context.Background(), stop: func() bool { return true }, release: refs.Done}
+			}
+
+			if _, err := io.WriteString(w, data); err != nil {
+				t.Fatal(err)
+			}
+			var file string
+			eventually(t, "the buffering of the body", func() bool {
+				b.mu.Lock()
+				defer b.mu.Unlock()
+				if b.file != nil {
+					file = b.file.Name()
+				}
+				return b.size == int64(tt.size)
+			})
+			if (file != "") != tt.wantFile {
+				t.Fatalf("spilled to a file = %v, want %v", file != "", tt.wantFile)
+			}
+
+			if tt.closeEarly {
+				for _, r := range readers {
+					r.Close()
+				}
+				<-filled
+				w.Close()
+			} else {
+				w.Close()
+				for _, r := range readers {
+					wg.Add(1)
+					go func() {
+						defer wg.Done()
+						defer r.Close()
+						got, err := io.ReadAll(r)
+						if err != nil || string(got) != data {
+							t.Errorf("body = %q, %v, want %q", got, err, data)
+						}
+					}()
+				}
+				wg.Wait()
+			}
+
+			if file != "" {
+				eventually(t, "the removal of the temporary file", func() bool {
+					_, err := os.Stat(file)
+					return errors.Is(err, os.ErrNotExist)
+				})
+			}
+		})
+	}
+}
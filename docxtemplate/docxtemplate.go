@@ -0,0 +1,369 @@
+// Package docxtemplate fills DOCX and ODT templates in memory before their conversion to PDF.
+//
+// Templates use the following markers, which may be split across runs by word processors:
+//
+//	{{customer.name}}      replaced by the value at the given path
+//	{{#row items}}         in a table row, repeats the row for each element of the slice
+//	{{.price}}             in a repeated row, the value at the given path of the current element
+//	{{#if paid}}...{{/if}} keeps its content only if the value is truthy
+//
+// Values are looked up in maps by key and in structs by field name.
+// Conditional markers must either share a paragraph or each fill a paragraph of their own.
+package docxtemplate
+
+import (
+	"archive/zip"
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"regexp"
+	"strings"
+
+	"github.com/nativebpm/gotenberg/v8"
+)
+
+// dialect describes the XML vocabulary of a document format.
+type dialect struct {
+	ext       string
+	parts     *regexp.Regexp
+	paragraph *regexp.Regexp
+	row       *regexp.Regexp
+	// text matches the text nodes of a paragraph; the second group is the text content.
+	text *regexp.Regexp
+	// preserve makes whitespace at the edges of text nodes significant, as moved and
+	// replaced text may now start or end with spaces.
+	preserve *strings.Replacer
+}
+
+var (
+	docx = dialect{
+		ext:       ".docx",
+		parts:     regexp.MustCompile(`^word/(document|header\d*|footer\d*|footnotes|endnotes)\.xml$`),
+		paragraph: regexp.MustCompile(`(?s)<w:p[ >].*?</w:p>`),
+		row:       regexp.MustCompile(`(?s)<w:tr[ >].*?</w:tr>`),
+		text:      regexp.MustCompile(`(<w:t(?:\s[^>]*)?>)([^<]*)</w:t>`),
+		preserve:  strings.NewReplacer("<w:t>", `<w:t xml:space="preserve">`),
+	}
+	odt = dialect{
+		ext:       ".odt",
+		parts:     regexp.MustCompile(`^(content|styles)\.xml$`),
+		paragraph: regexp.MustCompile(`(?s)<text:(?:p|h)[ >].*?</text:(?:p|h)>`),
+		row:       regexp.MustCompile(`(?s)<table:table-row[ >].*?</table:table-row>`),
+		text:      regexp.MustCompile(`(>)([^<]+)`),
+		preserve:  strings.NewReplacer(),
+	}
+)
+
+var (
+	tagPattern   = regexp.MustCompile(`\{\{[^{}]*\}\}`)
+	rowMarker    = regexp.MustCompile(`\{\{#row\s+([^{}\s]+)\s*\}\}`)
+	ifMarker     = regexp.MustCompile(`\{\{#if\s+([^{}\s]+)\s*\}\}`)
+	endIfMarker  = regexp.MustCompile(`\{\{/if\s*\}\}`)
+	placeholders = regexp.MustCompile(`\{\{\s*([^{}#/\s][^{}\s]*)\s*\}\}`)
+)
+
+// Template is a DOCX or ODT document holding template markers.
+type Template struct {
+	name    string
+	dialect dialect
+	files   []*zip.File
+}
+
+// Open reads a DOCX or ODT template. The name is used as the filename of the filled document.
+func Open(name string, r io.ReaderAt, size int64) (*Template, error) {
+	zr, err := zip.NewReader(r, size)
+	if err != nil {
+		return nil, fmt.Errorf("docxtemplate: %s: %w", name, err)
+	}
+
+	t := &Template{name: name, files: zr.File}
+	for _, f := range zr.File {
+		switch f.Name {
+		case "word/document.xml":
+			t.dialect = docx
+		case "content.xml":
+			t.dialect = odt
+		}
+	}
+	if t.dialect.ext == "" {
+		return nil, fmt.Errorf("docxtemplate: %s: neither a DOCX nor an ODT document", name)
+	}
+	if ext := path.Ext(name); !strings.EqualFold(ext, t.dialect.ext) {
+		t.name = strings.TrimSuffix(name, ext) + t.dialect.ext
+	}
+	return t, nil
+}
+
+// OpenBytes reads a DOCX or ODT template from memory.
+func OpenBytes(name string, b []byte) (*Template, error) {
+	return Open(name, bytes.NewReader(b), int64(len(b)))
+}
+
+// OpenFile reads a DOCX or ODT template from the filesystem.
+func OpenFile(filename string) (*Template, error) {
+	b, err := os.ReadFile(filename)
+	if err != nil {
+		return nil, err
+	}
+	return OpenBytes(path.Base(filename), b)
+}
+
+// Name returns the filename of the filled document.
+func (t *Template) Name() string {
+	return t.name
+}
+
+// Execute fills the template with data and writes the resulting document to w.
+func (t *Template) Execute(w io.Writer, data any) error {
+	zw := zip.NewWriter(w)
+	for _, f := range t.files {
+		b, err := readFile(f)
+		if err != nil {
+			return err
+		}
+		if t.dialect.parts.MatchString(f.Name) {
+			if b, err = t.dialect.fill(b, data); err != nil {
+				return fmt.Errorf("docxtemplate: %s: %s: %w", t.name, f.Name, err)
+			}
+		}
+
+		// ODF requires the mimetype entry to stay first and uncompressed, which copying headers preserves.
+		fw, err := zw.CreateHeader(&zip.FileHeader{Name: f.Name, Method: f.Method, Modified: f.Modified})
+		if err != nil {
+			return err
+		}
+		if _, err := fw.Write(b); err != nil {
+			return err
+		}
+	}
+	return zw.Close()
+}
+
+// With returns a gotenberg.FileSource yielding the template filled with data,
+// to be passed to LibreOffice.FileSource.
+func (t *Template) With(data any) gotenberg.FileSource {
+	return source{t: t, data: data}
+}
+
+// source is a template bound to its data.
+type source struct {
+	t    *Template
+	data any
+}
+
+// Open implements the gotenberg.FileSource interface.
+func (s source) Open() (gotenberg.NamedFile, error) {
+	var buf bytes.Buffer
+	if err := s.t.Execute(&buf, s.data); err != nil {
+		return gotenberg.NamedFile{}, err
+	}
+	return gotenberg.NamedFile{Name: s.t.name, Content: &buf}, nil
+}
+
+// fill processes the markers of an XML part.
+func (d dialect) fill(b []byte, data any) ([]byte, error) {
+	s := d.paragraph.ReplaceAllStringFunc(string(b), d.joinTags)
+
+	s, err := d.repeatRows(s, data)
+	if err != nil {
+		return nil, err
+	}
+	if s, err = d.conditionals(s, data); err != nil {
+		return nil, err
+	}
+	if s, err = replacePlaceholders(s, data, nil); err != nil {
+		return nil, err
+	}
+	return []byte(d.preserve.Replace(s)), nil
+}
+
+// joinTags moves markers split across several text nodes of a paragraph into the first of them.
+func (d dialect) joinTags(p string) string {
+	nodes := d.text.FindAllStringSubmatchIndex(p, -1)
+	if len(nodes) < 2 {
+		return p
+	}
+
+	// owner maps each character of the paragraph text to the text node it belongs to.
+	var text strings.Builder
+	var owner []int
+	for i, n := range nodes {
+		text.WriteString(p[n[4]:n[5]])
+		for range n[5] - n[4] {
+			owner = append(owner, i)
+		}
+	}
+	tags := tagPattern.FindAllStringIndex(text.String(), -1)
+	if len(tags) == 0 {
+		return p
+	}
+
+	contents := make([]strings.Builder, len(nodes))
+	t := 0
+	for i, c := range []byte(text.String()) {
+		for t < len(tags) && i >= tags[t][1] {
+			t++
+		}
+		o := owner[i]
+		if t < len(tags) && i >= tags[t][0] {
+			o = owner[tags[t][0]]
+		}
+		contents[o].WriteByte(c)
+	}
+
+	var out strings.Builder
+	last := 0
+	for i, n := range nodes {
+		out.WriteString(p[last:n[4]])
+		out.WriteString(contents[i].String())
+		last = n[5]
+	}
+	out.WriteString(p[last:])
+	return out.String()
+}
+
+// paragraphText returns the text of a paragraph.
+func (d dialect) paragraphText(p string) string {
+	var text strings.Builder
+	for _, m := range d.text.FindAllStringSubmatch(p, -1) {
+		text.WriteString(m[2])
+	}
+	return text.String()
+}
+
+// repeatRows repeats the table rows holding a row marker for each element of the slice it names.
+func (d dialect) repeatRows(s string, data any) (string, error) {
+	var errs []error
+	s = d.row.ReplaceAllStringFunc(s, func(row string) string {
+		m := rowMarker.FindStringSubmatch(row)
+		if m == nil {
+			return row
+		}
+		row = strings.Replace(row, m[0], "", 1)
+
+		items, err := elements(data, m[1])
+		if err != nil {
+			errs = append(errs, err)
+			return ""
+		}
+
+		var out strings.Builder
+		for _, item := range items {
+			filled, err := replacePlaceholders(row, data, item)
+			if err != nil {
+				errs = append(errs, err)
+				return ""
+			}
+			out.WriteString(filled)
+		}
+		return out.String()
+	})
+	return s, errors.Join(errs...)
+}
+
+// conditionals resolves conditional blocks, innermost first.
+func (d dialect) conditionals(s string, data any) (string, error) {
+	for {
+		end := endIfMarker.FindStringIndex(s)
+		opens := ifMarker.FindAllStringSubmatchIndex(s, -1)
+		if end == nil && len(opens) == 0 {
+			return s, nil
+		}
+
+		var open []int
+		for _, o := range opens {
+			if end != nil && o[0] < end[0] {
+				open = o
+			}
+		}
+		if end == nil || open == nil {
+			return "", errors.New("unbalanced {{#if}} and {{/if}} markers")
+		}
+
+		value, err := lookup(data, nil, s[open[2]:open[3]])
+		if err != nil {
+			return "", err
+		}
+
+		openStart, openEnd := d.markerBounds(s, open[0], open[1])
+		endStart, endEnd := d.markerBounds(s, end[0], end[1])
+		if !balanced(s[openEnd:endStart]) {
+			return "", fmt.Errorf("conditional %q must either share a paragraph with its end or fill its own paragraph", s[open[0]:open[1]])
+		}
+		if truthy(value) {
+			s = s[:openStart] + s[openEnd:endStart] + s[endEnd:]
+		} else {
+			s = s[:openStart] + s[endEnd:]
+		}
+	}
+}
+
+// markerBounds returns the bounds of a marker, extended to its paragraph if it is the only text in it.
+func (d dialect) markerBounds(s string, start, end int) (int, int) {
+	for _, p := range d.paragraph.FindAllStringIndex(s, -1) {
+		if p[0] <= start && end <= p[1] {
+			if strings.TrimSpace(d.paragraphText(s[p[0]:p[1]])) == s[start:end] {
+				return p[0], p[1]
+			}
+			break
+		}
+	}
+	return start, end
+}
+
+// xmlTag matches XML tags, capturing the closing slash, the name and the self-closing slash.
+var xmlTag = regexp.MustCompile(`<(/?)([\w:.-]+)[^>]*?(/?)>`)
+
+// balanced reports whether every element opened in the XML fragment is closed in it, and conversely.
+func balanced(fragment string) bool {
+	var stack []string
+	for _, m := range xmlTag.FindAllStringSubmatch(fragment, -1) {
+		switch {
+		case m[3] == "/":
+		case m[1] == "/":
+			if len(stack) == 0 || stack[len(stack)-1] != m[2] {
+				return false
+			}
+			stack = stack[:len(stack)-1]
+		default:
+			stack = append(stack, m[2])
+		}
+	}
+	return len(stack) == 0
+}
+
+// replacePlaceholders replaces placeholders with their XML-escaped values.
+// Paths starting with a dot are looked up in item.
+func replacePlaceholders(s string, data, item any) (string, error) {
+	var errs []error
+	s = placeholders.ReplaceAllStringFunc(s, func(tag string) string {
+		p := placeholders.FindStringSubmatch(tag)[1]
+		if item == nil && strings.HasPrefix(p, ".") {
+			// Element placeholders are filled when their row is repeated.
+			return tag
+		}
+		value, err := lookup(data, item, p)
+		if err != nil {
+			errs = append(errs, err)
+			return tag
+		}
+		var escaped bytes.Buffer
+		_ = xmlEscape(&escaped, format(value))
+		return escaped.String()
+	})
+	return s, errors.Join(errs...)
+}
+
+// readFile reads the content of a ZIP archive entry.
+func readFile(f *zip.File) ([]byte, error) {
+	rc, err := f.Open()
+	if err != nil {
+		return nil, err
+	}
+	defer rc.Close()
+
+	return io.ReadAll(rc)
+}
@@ -0,0 +1,210 @@
+package docxtemplate
+
+import (
+	"archive/zip"
+	"bytes"
+	"fmt"
+	"io"
+	"strings"
+	"testing"
+)
+
+// run wraps text in a WordprocessingML run.
+func run(text string) string {
+	return "<w:r><w:t>" + text + "</w:t></w:r>"
+}
+
+// para wraps runs in a WordprocessingML paragraph.
+func para(runs ...string) string {
+	return "<w:p>" + strings.Join(runs, "") + "</w:p>"
+}
+
+// row wraps paragraphs in a table row of a single cell.
+func row(paras ...string) string {
+	return "<w:tr><w:tc>" + strings.Join(paras, "") + "</w:tc></w:tr>"
+}
+
+type invoice struct {
+	Customer struct{ Name string }
+	Items    []map[string]any
+	Paid     bool
+	Note     *string
+}
+
+func TestFillDOCX(t *testing.T) {
+	inv := invoice{Paid: true, Items: []map[string]any{{"name": "Pens", "price": 3}, {"name": "Ink & paper", "price": 12.5}}}
+	inv.Customer.Name = "ACME <Ltd>"
+
+	tests := []struct {
+		name string
+		in   string
+		data any
+		want string
+		err  string
+	}{
+		{
+			name: "placeholder",
+			in:   para(run("Dear {{customer.name}},")),
+			data: inv,
+			want: para(`<w:r><w:t xml:space="preserve">Dear ACME &lt;Ltd&gt;,</w:t></w:r>`),
+		},
+		{
+			name: "placeholder with spaces",
+			in:   para(run("{{ customer.name }}")),
+			data: inv,
+			want: para(`<w:r><w:t xml:space="preserve">ACME &lt;Ltd&gt;</w:t></w:r>`),
+		},
+		{
+			name: "placeholder split across runs",
+			in:   para(run("Dear {{cust"), run("omer."), run("name}}!")),
+			data: inv,
+			want: para(
+				`<w:r><w:t xml:space="preserve">Dear ACME &lt;Ltd&gt;</w:t></w:r>`,
+				`<w:r><w:t xml:space="preserve"></w:t></w:r>`,
+				`<w:r><w:t xml:space="preserve">!</w:t></w:r>`,
+			),
+		},
+		{
+			name: "map data",
+			in:   para(run("{{a.b}}")),
+			data: map[string]any{"a": map[string]int{"b": 7}},
+			want: para(`<w:r><w:t xml:space="preserve">7</w:t></w:r>`),
+		},
+		{
+			name: "nil pointer is empty",
+			in:   para(run("[{{note}}]")),
+			data: inv,
+			want: para(`<w:r><w:t xml:space="preserve">[]</w:t></w:r>`),
+		},
+		{
+			name: "repeated row",
+			in:   "<w:tbl>" + row(para(run("{{#row items}}{{.name}}")), para(run("{{.price}}"))) + "</w:tbl>",
+			data: inv,
+			want: "<w:tbl>" +
+				row(para(`<w:r><w:t xml:space="preserve">Pens</w:t></w:r>`), para(`<w:r><w:t xml:space="preserve">3</w:t></w:r>`)) +
+				row(para(`<w:r><w:t xml:space="preserve">Ink &amp; paper</w:t></w:r>`), para(`<w:r><w:t xml:space="preserve">12.5</w:t></w:r>`)) +
+				"</w:tbl>",
+		},
+		{
+			name: "repeated row over an empty slice",
+			in:   "<w:tbl>" + row(para(run("{{#row items}}{{.name}}"))) + "</w:tbl>",
+			data: invoice{},
+			want: "<w:tbl></w:tbl>",
+		},
+		{
+			name: "inline conditional kept",
+			in:   para(run("Status: {{#if paid}}paid{{/if}}.")),
+			data: inv,
+			want: para(`<w:r><w:t xml:space="preserve">Status: paid.</w:t></w:r>`),
+		},
+		{
+			name: "inline conditional dropped",
+			in:   para(run("Status: {{#if paid}}paid{{/if}}.")),
+			data: invoice{},
+			want: para(`<w:r><w:t xml:space="preserve">Status: .</w:t></w:r>`),
+		},
+		{
+			name: "conditional paragraphs dropped",
+			in:   para(run("a")) + para(run("{{#if paid}}")) + para(run("b")) + para(run("{{/if}}")) + para(run("c")),
+			data: invoice{},
+			want: para(`<w:r><w:t xml:space="preserve">a</w:t></w:r>`) + para(`<w:r><w:t xml:space="preserve">c</w:t></w:r>`),
+		},
+		{
+			name: "conditional paragraphs kept",
+			in:   para(run("{{#if paid}}")) + para(run("b")) + para(run("{{/if}}")),
+			data: inv,
+			want: para(`<w:r><w:t xml:space="preserve">b</w:t></w:r>`),
+		},
+		{
+			name: "nested conditionals",
+			in:   para(run("{{#if paid}}x{{#if note}}y{{/if}}z{{/if}}")),
+			data: inv,
+			want: para(`<w:r><w:t xml:space="preserve">xz</w:t></w:r>`),
+		},
+		{
+			name: "missing key",
+			in:   para(run("{{customer.phone}}")),
+			data: inv,
+			err:  `customer.phone: missing field "phone"`,
+		},
+		{
+			name: "missing row slice",
+			in:   "<w:tbl>" + row(para(run("{{#row lines}}{{.name}}"))) + "</w:tbl>",
+			data: inv,
+			err:  `lines: missing field "lines"`,
+		},
+		{
+			name: "unbalanced conditional",
+			in:   para(run("{{#if paid}}")),
+			data: inv,
+			err:  "unbalanced",
+		},
+		{
+			name: "conditional across paragraphs",
+			in:   para(run("a {{#if paid}}")) + para(run("b {{/if}}")),
+			data: inv,
+			err:  "must either share a paragraph",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := docx.fill([]byte(tt.in), tt.data)
+			if tt.err != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.err) {
+					t.Fatalf("error = %v, want %q", err, tt.err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if string(got) != tt.want {
+				t.Errorf("got\n%s\nwant\n%s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestFillODT(t *testing.T) {
+	in := `<text:p>Dear <text:span>{{na</text:span>me}}</text:p>` +
+		`<table:table-row><table:table-cell><text:p>{{#row items}}{{.}}</text:p></table:table-cell></table:table-row>`
+	want := `<text:p>Dear <text:span>Ann</text:span></text:p>` +
+		`<table:table-row><table:table-cell><text:p>a</text:p></table:table-cell></table:table-row>` +
+		`<table:table-row><table:table-cell><text:p>b</text:p></table:table-cell></table:table-row>`
+
+	got, err := odt.fill([]byte(in), map[string]any{"name": "Ann", "items": []string{"a", "b"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(got) != want {
+		t.Errorf("got\n%s\nwant\n%s", got, want)
+	}
+}
+
+func Example() {
+	// A minimal DOCX holding a placeholder split across runs, as word processors often write it.
+	var doc bytes.Buffer
+	zw := zip.NewWriter(&doc)
+	w, _ := zw.Create("word/document.xml")
+	io.WriteString(w, `<w:document><w:body><w:p><w:r><w:t>Hello {{na</w:t></w:r><w:r><w:t>me}}!</w:t></w:r></w:p></w:body></w:document>`)
+	zw.Close()
+
+	t, err := OpenBytes("letter.docx", doc.Bytes())
+	if err != nil {
+		panic(err)
+	}
+	var out bytes.Buffer
+	if err := t.Execute(&out, map[string]string{"name": "World"}); err != nil {
+		panic(err)
+	}
+
+	zr, _ := zip.NewReader(bytes.NewReader(out.Bytes()), int64(out.Len()))
+	rc, _ := zr.File[0].Open()
+	filled, _ := io.ReadAll(rc)
+	fmt.Println(t.Name())
+	fmt.Println(string(filled))
+	// Output:
+	// letter.docx
+	// <w:document><w:body><w:p><w:r><w:t xml:space="preserve">Hello World</w:t></w:r><w:r><w:t xml:space="preserve">!</w:t></w:r></w:p></w:body></w:document>
+}
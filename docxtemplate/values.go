@@ -0,0 +1,106 @@
+package docxtemplate
+
+import (
+	"encoding/xml"
+	"fmt"
+	"io"
+	"reflect"
+	"strings"
+)
+
+// lookup resolves a dotted path in data, or in item if the path starts with a dot.
+func lookup(data, item any, path string) (any, error) {
+	value := data
+	if strings.HasPrefix(path, ".") {
+		value = item
+		path = strings.TrimPrefix(path, ".")
+	}
+	if path == "" {
+		return value, nil
+	}
+
+	for _, key := range strings.Split(path, ".") {
+		v := indirect(reflect.ValueOf(value))
+		switch v.Kind() {
+		case reflect.Map:
+			if v.Type().Key().Kind() != reflect.String {
+				return nil, fmt.Errorf("%s: unsupported map key type %s", path, v.Type().Key())
+			}
+			e := v.MapIndex(reflect.ValueOf(key).Convert(v.Type().Key()))
+			if !e.IsValid() {
+				return nil, fmt.Errorf("%s: missing key %q", path, key)
+			}
+			value = e.Interface()
+		case reflect.Struct:
+			f := v.FieldByNameFunc(func(name string) bool { return strings.EqualFold(name, key) })
+			if !f.IsValid() || !f.CanInterface() {
+				return nil, fmt.Errorf("%s: missing field %q", path, key)
+			}
+			value = f.Interface()
+		default:
+			return nil, fmt.Errorf("%s: cannot look up %q in %s", path, key, v.Kind())
+		}
+	}
+	return value, nil
+}
+
+// elements resolves a path to a slice or an array and returns its elements.
+func elements(data any, path string) ([]any, error) {
+	value, err := lookup(data, nil, path)
+	if err != nil {
+		return nil, err
+	}
+
+	v := indirect(reflect.ValueOf(value))
+	if v.Kind() != reflect.Slice && v.Kind() != reflect.Array {
+		if !v.IsValid() {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("%s: %s is not a slice", path, v.Kind())
+	}
+
+	items := make([]any, v.Len())
+	for i := range items {
+		items[i] = v.Index(i).Interface()
+	}
+	return items, nil
+}
+
+// truthy reports whether a value enables a conditional block:
+// zero values, nil and empty collections are false.
+func truthy(value any) bool {
+	v := indirect(reflect.ValueOf(value))
+	switch v.Kind() {
+	case reflect.Invalid:
+		return false
+	case reflect.Slice, reflect.Map, reflect.Array, reflect.String:
+		return v.Len() > 0
+	default:
+		return !v.IsZero()
+	}
+}
+
+// format formats a value as text.
+func format(value any) string {
+	v := indirect(reflect.ValueOf(value))
+	if !v.IsValid() {
+		return ""
+	}
+	return fmt.Sprint(v.Interface())
+}
+
+// indirect dereferences pointers and interfaces.
+func indirect(v reflect.Value) reflect.Value {
+	for v.Kind() == reflect.Pointer || v.Kind() == reflect.Interface {
+		if v.IsNil() {
+			return reflect.Value{}
+		}
+		v = v.Elem()
+	}
+	return v
+}
+
+// xmlEscape writes the text escaped for XML character data.
+func xmlEscape(w io.Writer, s string) error {
+	return xml.EscapeText(w, []byte(s))
+}
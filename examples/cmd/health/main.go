@@ -24,7 +24,7 @@ func main() {
 
 	fmt.Printf("Health Status: %s\n", health.Status)
 	for module, status := range health.Details {
-		fmt.Printf("  %s: %v\n", module, status)
+		fmt.Printf("  %s: %s\n", module, status.Status)
 	}
 
 	version, err := client.GetVersion(ctx)
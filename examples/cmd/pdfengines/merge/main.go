@@ -34,55 +34,15 @@ func main() {
 <body><h1>Second Page</h1><p>This is the second PDF.</p></body>
 </html>`
 
-	// First, convert HTML to PDFs using Chromium
-	pdf1Resp, err := client.Chromium().
-		ConvertHTML(context.Background(), strings.NewReader(html1)).
-		Send()
-	if err != nil {
-		log.Fatal(err)
-	}
-	defer pdf1Resp.Body.Close()
-
-	pdf2Resp, err := client.Chromium().
-		ConvertHTML(context.Background(), strings.NewReader(html2)).
-		Send()
-	if err != nil {
-		log.Fatal(err)
-	}
-	defer pdf2Resp.Body.Close()
-
-	// Read PDF data
-	pdf1Data := make([]byte, 0)
-	buf1 := make([]byte, 1024)
-	for {
-		n, err := pdf1Resp.Body.Read(buf1)
-		if n > 0 {
-			pdf1Data = append(pdf1Data, buf1[:n]...)
-		}
-		if err != nil {
-			break
-		}
-	}
-
-	pdf2Data := make([]byte, 0)
-	buf2 := make([]byte, 1024)
-	for {
-		n, err := pdf2Resp.Body.Read(buf2)
-		if n > 0 {
-			pdf2Data = append(pdf2Data, buf2[:n]...)
-		}
-		if err != nil {
-			break
-		}
-	}
-
-	// Merge the PDFs
-	mergedResp, err := client.PDFEngines().
-		Merge(context.Background()).
-		File("pdf1.pdf", strings.NewReader(string(pdf1Data))).
-		File("pdf2.pdf", strings.NewReader(string(pdf2Data))).
-		Send()
+	// Convert both HTML documents with Chromium and stream the PDFs into the merge,
+	// in the given order, without reading them into memory
+	pipeline := client.Pipeline()
+	merged := pipeline.Merge("merged.pdf",
+		pipeline.HTML("pdf1.pdf", strings.NewReader(html1), nil),
+		pipeline.HTML("pdf2.pdf", strings.NewReader(html2), nil),
+	)
 
+	mergedResp, err := pipeline.Run(context.Background(), merged)
 	if err != nil {
 		log.Fatal(err)
 	}
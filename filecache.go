@@ -0,0 +1,161 @@
+package gotenberg
+
+import (
+	"bufio"
+	"encoding/json"
+	"io"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+)
+
+// FileCache is a cache storing entries as files of a directory, evicting the least recently
+// used ones beyond a total size. It can be shared by the processes using the directory.
+type FileCache struct {
+	dir      string
+	maxBytes int64
+	ttl      time.Duration
+	mu       sync.Mutex
+}
+
+// fileCacheMeta is the first line of a cache file, followed by the body.
+type fileCacheMeta struct {
+	StatusCode int                 `json:"statusCode"`
+	Header     map[string][]string `json:"header"`
+	Created    time.Time           `json:"created"`
+}
+
+// fileCacheExt is the extension of the cache files.
+const fileCacheExt = ".gotenberg-cache"
+
+// NewFileCache returns a cache storing up to maxBytes of files in dir, created if needed,
+// whose entries expire after ttl. A zero maxBytes or ttl means no limit.
+func NewFileCache(dir string, maxBytes int64, ttl time.Duration) (*FileCache, error) {
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, err
+	}
+	return &FileCache{dir: dir, maxBytes: maxBytes, ttl: ttl}, nil
+}
+
+// path returns the path of the file of a key.
+func (c *FileCache) path(key string) string {
+	return filepath.Join(c.dir, key+fileCacheExt)
+}
+
+// Get implements the Cache interface.
+func (c *FileCache) Get(key string) (*CacheEntry, bool) {
+	path := c.path(key)
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, false
+	}
+	defer f.Close()
+
+	br := bufio.NewReader(f)
+	line, err := br.ReadBytes('\n')
+	if err != nil {
+		return nil, false
+	}
+	var meta fileCacheMeta
+	if err := json.Unmarshal(line, &meta); err != nil {
+		return nil, false
+	}
+	if c.ttl > 0 && time.Since(meta.Created) > c.ttl {
+		os.Remove(path)
+		return nil, false
+	}
+	body, err := io.ReadAll(br)
+	if err != nil {
+		return nil, false
+	}
+
+	// The modification time orders the files for eviction.
+	now := time.Now()
+	os.Chtimes(path, now, now)
+	return &CacheEntry{StatusCode: meta.StatusCode, Header: meta.Header, Body: body, Created: meta.Created}, true
+}
+
+// MaxBodySize returns the size of the largest body the cache stores, zero if unlimited.
+func (c *FileCache) MaxBodySize() int64 {
+	return c.maxBytes
+}
+
+// Set implements the Cache interface. Entries larger than the cache and write failures are not stored.
+func (c *FileCache) Set(key string, entry *CacheEntry) {
+	if c.maxBytes > 0 && int64(len(entry.Body)) > c.maxBytes {
+		return
+	}
+	line, err := json.Marshal(fileCacheMeta{StatusCode: entry.StatusCode, Header: entry.Header, Created: entry.Created})
+	if err != nil {
+		return
+	}
+
+	// Write to a temporary file renamed into place, so that readers never see partial entries.
+	tmp, err := os.CreateTemp(c.dir, ".tmp-*")
+	if err != nil {
+		return
+	}
+	_, err = tmp.Write(append(line, '\n'))
+	if err == nil {
+		_, err = tmp.Write(entry.Body)
+	}
+	if cerr := tmp.Close(); err == nil {
+		err = cerr
+	}
+	if err == nil {
+		err = os.Rename(tmp.Name(), c.path(key))
+	}
+	if err != nil {
+		os.Remove(tmp.Name())
+		return
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.evict()
+}
+
+// evict removes the least recently used files beyond the size limit, and the expired ones.
+func (c *FileCache) evict() {
+	if c.maxBytes <= 0 && c.ttl <= 0 {
+		return
+	}
+	dirEntries, err := os.ReadDir(c.dir)
+	if err != nil {
+		return
+	}
+
+	type file struct {
+		path    string
+		size    int64
+		modTime time.Time
+	}
+	var files []file
+	var size int64
+	for _, e := range dirEntries {
+		if !strings.HasSuffix(e.Name(), fileCacheExt) {
+			continue
+		}
+		info, err := e.Info()
+		if err != nil {
+			continue
+		}
+		files = append(files, file{filepath.Join(c.dir, e.Name()), info.Size(), info.ModTime()})
+		size += info.Size()
+	}
+	slices.SortFunc(files, func(a, b file) int { return a.modTime.Compare(b.modTime) })
+
+	for _, f := range files {
+		// Entries are created at most at their last modification, so older files are expired.
+		expired := c.ttl > 0 && time.Since(f.modTime) > c.ttl
+		if !expired && (c.maxBytes <= 0 || size <= c.maxBytes) {
+			break
+		}
+		if os.Remove(f.path) == nil {
+			size -= f.size
+		}
+	}
+}
@@ -0,0 +1,310 @@
+package gotenberg
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/csv"
+	"fmt"
+	"io"
+	"path"
+	"strings"
+	"unicode/utf8"
+)
+
+// LibreOfficeFormats lists the extensions, without leading dot, accepted by the LibreOffice route.
+var LibreOfficeFormats = map[string]bool{
+	"123": true, "602": true, "abw": true, "bib": true, "bmp": true, "cdr": true, "cgm": true,
+	"cmx": true, "csv": true, "cwk": true, "dbf": true, "dif": true, "doc": true, "docm": true,
+	"docx": true, "dot": true, "dotm": true, "dotx": true, "dxf": true, "emf": true, "eps": true,
+	"epub": true, "fodg": true, "fodp": true, "fods": true, "fodt": true, "fopd": true, "gif": true,
+	"htm": true, "html": true, "hwp": true, "jpeg": true, "jpg": true, "key": true, "ltx": true,
+	"lwp": true, "mcw": true, "met": true, "mml": true, "mw": true, "numbers": true, "odd": true,
+	"odg": true, "odm": true, "odp": true, "ods": true, "odt": true, "otg": true, "oth": true,
+	"otp": true, "ots": true, "ott": true, "pages": true, "pbm": true, "pcd": true, "pct": true,
+	"pcx": true, "pdb": true, "pdf": true, "pgm": true, "png": true, "pot": true, "potm": true,
+	"potx": true, "ppm": true, "pps": true, "ppt": true, "pptm": true, "pptx": true, "psd": true,
+	"psw": true, "pub": true, "pwp": true, "pxl": true, "ras": true, "rtf": true, "sda": true,
+	"sdc": true, "sdd": true, "sdp": true, "sdw": true, "sgl": true, "slk": true, "smf": true,
+	"stc": true, "std": true, "sti": true, "stw": true, "svg": true, "svm": true, "swf": true,
+	"sxc": true, "sxd": true, "sxg": true, "sxi": true, "sxm": true, "sxw": true, "tga": true,
+	"tif": true, "tiff": true, "txt": true, "uof": true, "uop": true, "uos": true, "uot": true,
+	"vdx": true, "vor": true, "vsd": true, "vsdm": true, "vsdx": true, "wb2": true, "wk1": true,
+	"wks": true, "wmf": true, "wpd": true, "wpg": true, "wps": true, "xbm": true, "xhtml": true,
+	"xls": true, "xlsb": true, "xlsm": true, "xlsx": true, "xlt": true, "xltm": true, "xltx": true,
+	"xlw": true, "xml": true, "xpm": true, "zabw": true,
+}
+
+// container represents the file signature family of a format.
+type container int
+
+const (
+	containerUnknown container = iota
+	containerZip
+	containerOLE2
+	containerRTF
+	containerPDF
+	containerImage
+	containerText
+)
+
+// containers maps the extensions whose signature can be sniffed to their family.
+// Extensions missing from this map are never considered mismatched.
+var containers = map[string]container{
+	"docx": containerZip, "docm": containerZip, "dotx": containerZip, "dotm": containerZip,
+	"xlsx": containerZip, "xlsm": containerZip, "xltx": containerZip, "xltm": containerZip,
+	"pptx": containerZip, "pptm": containerZip, "potx": containerZip, "potm": containerZip,
+	"vsdx": containerZip, "vsdm": containerZip, "epub": containerZip,
+	"odt": containerZip, "ott": containerZip, "ods": containerZip, "ots": containerZip,
+	"odp": containerZip, "otp": containerZip, "odg": containerZip, "otg": containerZip,
+	"doc": containerOLE2, "dot": containerOLE2, "xls": containerOLE2, "xlt": containerOLE2,
+	"ppt": containerOLE2, "pps": containerOLE2, "pot": containerOLE2, "vsd": containerOLE2,
+	"rtf": containerRTF, "pdf": containerPDF,
+	"png": containerImage, "jpg": containerImage, "jpeg": containerImage, "gif": containerImage,
+	"bmp": containerImage, "tif": containerImage, "tiff": containerImage,
+	"csv": containerText, "txt": containerText, "htm": containerText, "html": containerText,
+	"xhtml": containerText, "xml": containerText,
+}
+
+// signatures maps the leading bytes of a file to its family and default extension.
+var signatures = []struct {
+	magic     string
+	container container
+	ext       string
+}{
+	{"PK\x03\x04", containerZip, ""},
+	{"\xD0\xCF\x11\xE0\xA1\xB1\x1A\xE1", containerOLE2, ""},
+	{`{\rtf`, containerRTF, "rtf"},
+	{"%PDF-", containerPDF, "pdf"},
+	{"\x89PNG\r\n\x1a\n", containerImage, "png"},
+	{"\xFF\xD8\xFF", containerImage, "jpg"},
+	{"GIF87a", containerImage, "gif"},
+	{"GIF89a", containerImage, "gif"},
+	{"II*\x00", containerImage, "tif"},
+	{"MM\x00*", containerImage, "tif"},
+}
+
+// odfMimetypes maps the mimetype entry of ODF and EPUB archives to their extension.
+var odfMimetypes = map[string]string{
+	"application/vnd.oasis.opendocument.text":                  "odt",
+	"application/vnd.oasis.opendocument.text-template":         "ott",
+	"application/vnd.oasis.opendocument.spreadsheet":           "ods",
+	"application/vnd.oasis.opendocument.spreadsheet-template":  "ots",
+	"application/vnd.oasis.opendocument.presentation":          "odp",
+	"application/vnd.oasis.opendocument.presentation-template": "otp",
+	"application/vnd.oasis.opendocument.graphics":              "odg",
+	"application/vnd.oasis.opendocument.graphics-template":     "otg",
+	"application/epub+zip":                                     "epub",
+}
+
+// ooxmlParts maps the top-level directory of OOXML archives to their extension.
+var ooxmlParts = map[string]string{
+	"word/":  "docx",
+	"xl/":    "xlsx",
+	"ppt/":   "pptx",
+	"visio/": "vsdx",
+}
+
+// ole2Streams maps the UTF-16 stream names of OLE2 compound files to their extension.
+var ole2Streams = []struct {
+	name string
+	ext  string
+}{
+	{"WordDocument", "doc"},
+	{"Workbook", "xls"},
+	{"Book", "xls"},
+	{"PowerPoint Document", "ppt"},
+	{"VisioDocument", "vsd"},
+}
+
+// sniffSize is the number of leading bytes used to identify a file.
+const sniffSize = 512
+
+// UnsupportedFormatError lists the files whose format is not supported by the LibreOffice route.
+type UnsupportedFormatError struct {
+	Files []string
+}
+
+// Error implements the error interface.
+func (e *UnsupportedFormatError) Error() string {
+	return fmt.Sprintf("unsupported LibreOffice format: %s", strings.Join(e.Files, ", "))
+}
+
+// DetectFormat identifies the format of a file for the LibreOffice route from its extension and content.
+// It returns the filename with a missing or incorrect extension corrected, and a reader yielding the
+// full content. An empty extension is returned if the format could not be identified as supported.
+func DetectFormat(filename string, content io.Reader) (string, string, io.Reader, error) {
+	ext := strings.ToLower(strings.TrimPrefix(path.Ext(filename), "."))
+
+	head := make([]byte, sniffSize)
+	n, err := io.ReadFull(content, head)
+	if err != nil && err != io.ErrUnexpectedEOF && err != io.EOF {
+		return filename, "", nil, err
+	}
+	head = head[:n]
+	content = io.MultiReader(bytes.NewReader(head), content)
+
+	sniffed, family := sniff(head)
+	if family == containerUnknown {
+		if LibreOfficeFormats[ext] {
+			return filename, ext, content, nil
+		}
+		return filename, "", content, nil
+	}
+	if LibreOfficeFormats[ext] && (containers[ext] == family || containers[ext] == containerUnknown) {
+		return filename, ext, content, nil
+	}
+
+	// The extension is missing, unsupported or contradicts the content: containers need
+	// to be inspected as a whole to tell their formats apart.
+	if family == containerZip || family == containerOLE2 {
+		b, err := io.ReadAll(content)
+		if err != nil {
+			return filename, "", nil, err
+		}
+		content = bytes.NewReader(b)
+		if family == containerZip {
+			sniffed = sniffZip(b)
+		} else {
+			sniffed = sniffOLE2(b)
+			// Password-protected OOXML documents are wrapped in an OLE2 compound file.
+			if sniffed == "" && containers[ext] == containerZip && bytes.Contains(b, utf16("EncryptedPackage")) {
+				return filename, ext, content, nil
+			}
+		}
+	}
+	if sniffed == "" {
+		return filename, "", content, nil
+	}
+
+	base := filename
+	if LibreOfficeFormats[ext] {
+		base = strings.TrimSuffix(filename, path.Ext(filename))
+	}
+	return base + "." + sniffed, sniffed, content, nil
+}
+
+// sniff identifies the family of a file, and its extension when the signature is enough to tell.
+func sniff(head []byte) (string, container) {
+	for _, s := range signatures {
+		if bytes.HasPrefix(head, []byte(s.magic)) {
+			return s.ext, s.container
+		}
+	}
+	if ext := sniffText(head); ext != "" {
+		return ext, containerText
+	}
+	return "", containerUnknown
+}
+
+// sniffText identifies HTML, XML, CSV and plain text files.
+func sniffText(head []byte) string {
+	if len(head) == 0 || bytes.IndexByte(head, 0) >= 0 {
+		return ""
+	}
+	// The sample may end in the middle of a multi-byte character.
+	if !utf8.Valid(head) && !utf8.Valid(head[:len(head)-min(len(head), utf8.UTFMax-1)]) {
+		return ""
+	}
+
+	lower := strings.ToLower(strings.TrimSpace(string(bytes.TrimPrefix(head, []byte("\xEF\xBB\xBF")))))
+	switch {
+	case strings.HasPrefix(lower, "<!doctype html"), strings.HasPrefix(lower, "<html"):
+		return "html"
+	case strings.HasPrefix(lower, "<?xml"):
+		return "xml"
+	case isCSV(head):
+		return "csv"
+	default:
+		return "txt"
+	}
+}
+
+// isCSV reports whether the sample consists of at least two complete records
+// with the same number of comma or semicolon separated fields.
+func isCSV(head []byte) bool {
+	// Drop the last line, which may be truncated.
+	if i := bytes.LastIndexByte(head, '\n'); i > 0 {
+		head = head[:i]
+	}
+	for _, comma := range []rune{',', ';'} {
+		r := csv.NewReader(bytes.NewReader(head))
+		r.Comma = comma
+		records, err := r.ReadAll()
+		if err == nil && len(records) >= 2 && len(records[0]) >= 2 {
+			return true
+		}
+	}
+	return false
+}
+
+// sniffZip identifies ODF, EPUB and OOXML archives.
+func sniffZip(b []byte) string {
+	zr, err := zip.NewReader(bytes.NewReader(b), int64(len(b)))
+	if err != nil {
+		return ""
+	}
+
+	for _, f := range zr.File {
+		if f.Name != "mimetype" {
+			continue
+		}
+		mimetype, err := readZipFile(f)
+		if err != nil {
+			return ""
+		}
+		return odfMimetypes[strings.TrimSpace(string(mimetype))]
+	}
+
+	for _, f := range zr.File {
+		for prefix, ext := range ooxmlParts {
+			if strings.HasPrefix(f.Name, prefix) {
+				return ext
+			}
+		}
+	}
+	return ""
+}
+
+// sniffOLE2 identifies Word, Excel, PowerPoint and Visio compound files from their stream names.
+func sniffOLE2(b []byte) string {
+	for _, s := range ole2Streams {
+		// Directory entries are NUL-terminated.
+		if bytes.Contains(b, append(utf16(s.name), 0, 0)) {
+			return s.ext
+		}
+	}
+	return ""
+}
+
+// utf16 encodes an ASCII stream name as in OLE2 directory entries.
+func utf16(name string) []byte {
+	b := make([]byte, 0, 2*len(name)+2)
+	for _, c := range name {
+		b = append(b, byte(c), 0)
+	}
+	return b
+}
+
+// detectFormats checks the files of a LibreOffice conversion, correcting their extensions.
+// Files whose format is not supported are reported together as an *UnsupportedFormatError.
+func (r *Request) detectFormats() error {
+	var unsupported []string
+	for i, f := range r.files {
+		if f.field != "files" {
+			continue
+		}
+		name, ext, content, err := DetectFormat(f.Name, f.Content)
+		if err != nil {
+			return fmt.Errorf("failed to read file %s: %w", f.Name, err)
+		}
+		if ext == "" {
+			unsupported = append(unsupported, f.Name)
+		}
+		r.files[i].Name, r.files[i].Content = name, content
+	}
+
+	if len(unsupported) > 0 {
+		return &UnsupportedFormatError{Files: unsupported}
+	}
+	return nil
+}
@@ -3,9 +3,16 @@
 package gotenberg
 
 import (
+	"context"
 	"encoding/json"
+	"errors"
+	"fmt"
 	"io"
+	"maps"
 	"net/http"
+	"strconv"
+	"strings"
+	"sync"
 	"time"
 
 	"github.com/nativebpm/httpstream"
@@ -17,11 +24,72 @@ type downloadFrom struct {
 	Headers map[string]string `json:"extraHttpHeaders,omitempty"`
 }
 
+// NamedFile is a file to upload, identified by its filename.
+type NamedFile struct {
+	Name    string
+	Content io.Reader
+}
+
+// FileSource provides a file to upload, possibly generated when the request is built.
+type FileSource interface {
+	Open() (NamedFile, error)
+}
+
+// Open implements the FileSource interface.
+func (f NamedFile) Open() (NamedFile, error) {
+	return f, nil
+}
+
+// ResponseError is returned when Gotenberg answers with a non-successful status code.
+type ResponseError struct {
+	StatusCode     int
+	GotenbergTrace string
+	Message        string
+}
+
+// Error implements the error interface.
+func (e *ResponseError) Error() string {
+	if e.Message == "" {
+		return fmt.Sprintf("gotenberg: unexpected status %d", e.StatusCode)
+	}
+	return fmt.Sprintf("gotenberg: unexpected status %d: %s", e.StatusCode, e.Message)
+}
+
 // Response represents a Gotenberg conversion response.
 // It wraps the HTTP response and provides access to the Gotenberg trace header.
 type Response struct {
 	*http.Response
 	GotenbergTrace string
+	// Cached reports whether the response was replayed from the cache of the client.
+	Cached bool
+	// Shared reports whether the response is the one of a concurrent identical request of another caller.
+	Shared bool
+}
+
+// checkStatus reports a non-successful status code as a *ResponseError.
+func (r *Response) checkStatus() error {
+	if r.StatusCode >= http.StatusOK && r.StatusCode < http.StatusMultipleChoices {
+		return nil
+	}
+
+	b, _ := io.ReadAll(io.LimitReader(r.Body, 4096))
+	return &ResponseError{
+		StatusCode:     r.StatusCode,
+		GotenbergTrace: r.GotenbergTrace,
+		Message:        strings.TrimSpace(string(b)),
+	}
+}
+
+// decodeJSON closes the response body and decodes it into v.
+// A non-successful status code is reported as a *ResponseError.
+func (r *Response) decodeJSON(v any) error {
+	defer r.Body.Close()
+
+	if err := r.checkStatus(); err != nil {
+		return err
+	}
+
+	return json.NewDecoder(r.Body).Decode(v)
 }
 
 // Request represents the base request builder carrying parameters and HTTP payload configurations.
@@ -31,6 +99,31 @@ type Request struct {
 	Wh         map[string]string
 	Meta       map[string]string
 	Df         []downloadFrom
+	typedMeta  map[string]any
+	client     *Client
+	ctx        context.Context
+	route      string
+	timeout    time.Duration
+	headers    http.Header
+	params     []formField
+	files      []formFile
+	ordered    bool
+	noCache    bool
+	progress   func(Progress)
+	since      map[string]Version
+	errs       []error
+}
+
+// formField represents a form parameter.
+type formField struct {
+	key, value string
+}
+
+// formFile represents a file uploaded under a given form field name.
+type formFile struct {
+	field    string
+	password secret
+	NamedFile
 }
 
 // Chromium represents a request builder specifically for Chromium-based PDF and screenshot conversions.
@@ -41,6 +134,8 @@ type Chromium struct {
 // LibreOffice represents a request builder specifically for Office document to PDF conversions.
 type LibreOffice struct {
 	*Request
+	password            secret
+	skipFormatDetection bool
 }
 
 // PDFEngines represents a request builder specifically for PDF engines actions (merge, split, flatten, bookmarks).
@@ -50,8 +145,19 @@ type PDFEngines struct {
 
 // Client is a Gotenberg HTTP client that wraps the base HTTP client
 // with Gotenberg-specific functionality for document conversion.
+// Its settings, such as Cache, Hooks or Throttle, must be set before it sends requests;
+// it is then safe for concurrent use.
 type Client struct {
 	HttpStream *httpstream.Client
+	policy     VersionPolicy
+	versionMu  sync.Mutex
+	version    *Version
+	throttler  *Throttler
+	cache      Cache
+	flights    *flightGroup
+	hooks      []Hooks
+	// traceExtractor extracts trace identifiers from the context of requests.
+	traceExtractor func(context.Context) string
 }
 
 // NewClient creates a new Gotenberg client with the given HTTP client and base URL.
@@ -76,27 +182,43 @@ func (c *Client) Use(middleware func(http.RoundTripper) http.RoundTripper) *Clie
 // Chromium returns a Request builder configured for Chromium operations.
 func (c *Client) Chromium() *Chromium {
 	return &Chromium{
-		Request: &Request{HttpStream: c.HttpStream},
+		Request: &Request{HttpStream: c.HttpStream, client: c},
 	}
 }
 
 // LibreOffice returns a Request builder configured for LibreOffice operations.
 func (c *Client) LibreOffice() *LibreOffice {
 	return &LibreOffice{
-		Request: &Request{HttpStream: c.HttpStream},
+		Request: &Request{HttpStream: c.HttpStream, client: c},
 	}
 }
 
 // PDFEngines returns a Request builder configured for PDF Engines operations.
 func (c *Client) PDFEngines() *PDFEngines {
 	return &PDFEngines{
-		Request: &Request{HttpStream: c.HttpStream},
+		Request: &Request{HttpStream: c.HttpStream, client: c},
 	}
 }
 
 // Send executes the request and returns the response.
 // It handles common fields like webhook headers, downloadFrom, and metadata.
 func (r *Request) Send() (*Response, error) {
+	r.propagateTrace()
+	in := r.instrument()
+	resp, err := r.send(in)
+	in.done(r, resp, err)
+	return resp, err
+}
+
+// send executes the request, reporting its steps to the hooks of the client.
+func (r *Request) send(in *instrumentation) (*Response, error) {
+	if err := errors.Join(r.errs...); err != nil {
+		return nil, err
+	}
+	if err := r.checkVersion(); err != nil {
+		return nil, err
+	}
+
 	// Dynamically marshal fields if they are set
 	for _, item := range []struct {
 		cond     bool
@@ -117,10 +239,10 @@ func (r *Request) Send() (*Response, error) {
 			val:      r.Df,
 		},
 		{
-			cond:     len(r.Meta) > 0,
+			cond:     len(r.Meta) > 0 || len(r.typedMeta) > 0,
 			isHeader: false,
 			key:      "metadata",
-			val:      r.Meta,
+			val:      r.metadata(),
 		},
 	} {
 		if item.cond {
@@ -129,51 +251,194 @@ func (r *Request) Send() (*Response, error) {
 				return nil, err
 			}
 			if item.isHeader {
-				r.Req.Header(item.key, string(b))
+				r.Header(item.key, string(b))
 			} else {
-				r.Req.Param(item.key, string(b))
+				r.Param(item.key, string(b))
+			}
+		}
+	}
+
+	in.build(r)
+
+	var cacheKey string
+	if r.cacheable() {
+		key, err := r.cacheKey()
+		if err != nil {
+			return nil, err
+		}
+		if r.client.cache != nil {
+			if e, ok := r.client.cache.Get(key); ok {
+				if r.progress != nil {
+					return newProgressTracker(r.progress).download(r.cachedResponse(e)), nil
+				}
+				return r.cachedResponse(e), nil
 			}
 		}
+		cacheKey = key
 	}
 
-	resp, err := r.Req.Send()
+	files, err := r.awaitInputs(r.uploads())
 	if err != nil {
 		return nil, err
 	}
 
-	return &Response{
+	ctx := r.ctx
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	// A shared request is sent on a context of its own, so that it outlives the caller sending it.
+	dedup := cacheKey != "" && r.client.flights != nil
+	sendCtx, cancel := ctx, context.CancelFunc(nil)
+	if dedup {
+		sendCtx, cancel = r.detach(ctx)
+	}
+
+	for _, p := range r.params {
+		r.Req.Param(p.key, p.value)
+	}
+	var tracker *progressTracker
+	if r.progress != nil {
+		tracker = newProgressTracker(r.progress)
+		files = tracker.uploads(files)
+	}
+	for _, f := range files {
+		r.Req.File(f.field, f.Name, f.Content)
+	}
+
+	send := func() (*http.Response, error) {
+		release, err := r.admit(sendCtx)
+		if err != nil {
+			return nil, err
+		}
+		in.send()
+		if tracker != nil {
+			tracker.sent()
+		}
+		resp, err := r.Req.Send()
+		release(resp, err)
+		if err != nil {
+			return nil, err
+		}
+		if cacheKey != "" && r.client.cache != nil {
+			r.cacheBody(cacheKey, resp)
+		}
+		return resp, nil
+	}
+
+	var resp *http.Response
+	var shared bool
+	if dedup {
+		resp, shared, err = r.client.flights.do(ctx, cacheKey, send, cancel, in.retry)
+	} else {
+		resp, err = send()
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	response := &Response{
 		Response:       resp,
 		GotenbergTrace: resp.Header.Get("Gotenberg-Trace"),
-	}, nil
+		Shared:         shared,
+	}
+	if tracker != nil {
+		return tracker.download(response), nil
+	}
+	return response, nil
+}
+
+// open starts a multipart request to the given route.
+func (r *Request) open(ctx context.Context, route string) *Request {
+	r.Req = r.HttpStream.Multipart(ctx, route)
+	r.ctx = ctx
+	r.route = route
+	return r
+}
+
+// detach starts the multipart request again on a context detached from ctx, with the same headers
+// and timeout, and returns the context along with the function cancelling it.
+func (r *Request) detach(ctx context.Context) (context.Context, context.CancelFunc) {
+	ctx, cancel := context.WithCancel(context.WithoutCancel(ctx))
+	r.Req = r.HttpStream.Multipart(ctx, r.route)
+	r.applyHeaders()
+	if r.timeout > 0 {
+		r.Req.Timeout(r.timeout)
+	}
+	return ctx, cancel
+}
+
+// clone starts a request to the given route carrying the headers, timeout, webhook headers,
+// metadata, required server versions, cache bypass and progress callback of r, but none of its
+// parameters and files.
+func (r *Request) clone(route string) *Request {
+	c := &Request{
+		HttpStream: r.HttpStream, Wh: r.Wh, Meta: r.Meta, typedMeta: r.typedMeta, client: r.client,
+		since: maps.Clone(r.since), noCache: r.noCache, progress: r.progress,
+	}
+	c.open(r.ctx, route)
+	c.headers = r.headers.Clone()
+	c.applyHeaders()
+	if r.timeout > 0 {
+		c.Timeout(r.timeout)
+	}
+	return c
+}
+
+// applyHeaders sets the headers of the request on its HTTP request, the values of multi-valued
+// headers joined with commas.
+func (r *Request) applyHeaders() {
+	for key, values := range r.headers {
+		r.Req.Header(key, strings.Join(values, ", "))
+	}
+}
+
+// fail records a validation error, reported by Send before anything is uploaded.
+func (r *Request) fail(err error) *Request {
+	r.errs = append(r.errs, err)
+	return r
+}
+
+// has reports whether a form parameter with the given key has been added.
+func (r *Request) has(key string) bool {
+	for _, p := range r.params {
+		if p.key == key {
+			return true
+		}
+	}
+	return false
 }
 
 // Header adds an HTTP header to the request.
 func (r *Request) Header(key, value string) *Request {
 	r.Req.Header(key, value)
+	if r.headers == nil {
+		r.headers = make(http.Header)
+	}
+	r.headers.Set(key, value)
 	return r
 }
 
 // Param adds a form parameter to the request.
+// Parameters are attached to the multipart body when the request is sent.
 func (r *Request) Param(key, value string) *Request {
-	r.Req.Param(key, value)
+	r.params = append(r.params, formField{key: key, value: value})
 	return r
 }
 
 // Bool adds a boolean form parameter to the request.
 func (r *Request) Bool(fieldName string, value bool) *Request {
-	r.Req.Bool(fieldName, value)
-	return r
+	return r.Param(fieldName, strconv.FormatBool(value))
 }
 
 // Float adds a float64 form parameter to the request.
 func (r *Request) Float(fieldName string, value float64) *Request {
-	r.Req.Float(fieldName, value)
-	return r
+	return r.Param(fieldName, strconv.FormatFloat(value, 'f', -1, 64))
 }
 
 // file adds a file to the request with a custom field name.
+// Files are attached to the multipart body when the request is sent.
 func (r *Request) file(fieldName, filename string, content io.Reader) *Request {
-	r.Req.File(fieldName, filename, content)
+	r.files = append(r.files, formFile{field: fieldName, NamedFile: NamedFile{Name: filename, Content: content}})
 	return r
 }
 
@@ -182,23 +447,32 @@ func (r *Request) File(filename string, content io.Reader) *Request {
 	return r.file("files", filename, content)
 }
 
+// FileSource adds a file provided by a FileSource to the conversion request.
+func (r *Request) FileSource(src FileSource) *Request {
+	f, err := src.Open()
+	if err != nil {
+		return r.fail(err)
+	}
+	return r.File(f.Name, f.Content)
+}
+
 // WebhookURL sets the webhook URL and HTTP method for successful operations.
 func (r *Request) WebhookURL(url, method string) *Request {
-	r.Req.Header("Gotenberg-Webhook-Url", url).
+	r.Header("Gotenberg-Webhook-Url", url).
 		Header("Gotenberg-Webhook-Method", method)
 	return r
 }
 
 // WebhookErrorURL sets the webhook URL and HTTP method for failed operations.
 func (r *Request) WebhookErrorURL(url, method string) *Request {
-	r.Req.Header("Gotenberg-Webhook-Error-Url", url).
+	r.Header("Gotenberg-Webhook-Error-Url", url).
 		Header("Gotenberg-Webhook-Error-Method", method)
 	return r
 }
 
 // WebhookEventsURL sets the webhook events URL for structured JSON event callbacks.
 func (r *Request) WebhookEventsURL(url string) *Request {
-	r.Req.Header("Gotenberg-Webhook-Events-Url", url)
+	r.Header("Gotenberg-Webhook-Events-Url", url)
 	return r
 }
 
@@ -219,19 +493,20 @@ func (r *Request) DownloadFrom(url string, headers map[string]string) *Request {
 
 // OutputFilename sets the output filename.
 func (r *Request) OutputFilename(filename string) *Request {
-	r.Req.Header("Gotenberg-Output-Filename", filename)
+	r.Header("Gotenberg-Output-Filename", filename)
 	return r
 }
 
 // Trace sets the request trace identifier for debugging and monitoring.
 func (r *Request) Trace(trace string) *Request {
-	r.Req.Header("Gotenberg-Trace", trace)
+	r.Header("Gotenberg-Trace", trace)
 	return r
 }
 
 // Timeout sets a timeout for the request.
 func (r *Request) Timeout(duration time.Duration) *Request {
 	r.Req.Timeout(duration)
+	r.timeout = duration
 	return r
 }
 
@@ -241,5 +516,32 @@ func (r *Request) Metadata(key, value string) *Request {
 		r.Meta = make(map[string]string)
 	}
 	r.Meta[key] = value
+	delete(r.typedMeta, key)
+	return r
+}
+
+// PDFMetadata sets typed metadata for the operation, preserving value types.
+// Entries override those previously set with Metadata, and the other way around.
+func (r *Request) PDFMetadata(m PDFMetadata) *Request {
+	if r.typedMeta == nil {
+		r.typedMeta = make(map[string]any)
+	}
+	for k, v := range m.Map() {
+		r.typedMeta[k] = v
+		delete(r.Meta, k)
+	}
 	return r
 }
+
+// metadata returns the metadata set with Metadata and PDFMetadata, the typed entries being kept
+// apart in typedMeta so that Meta keeps its map[string]string type.
+func (r *Request) metadata() map[string]any {
+	m := make(map[string]any, len(r.Meta)+len(r.typedMeta))
+	for k, v := range r.Meta {
+		m[k] = v
+	}
+	for k, v := range r.typedMeta {
+		m[k] = v
+	}
+	return m
+}
@@ -3,15 +3,65 @@ package gotenberg
 import (
 	"context"
 	"encoding/json"
+	"errors"
+	"fmt"
 	"io"
+	"strings"
+	"time"
 
 	"github.com/nativebpm/httpstream"
 )
 
+// Health statuses reported by Gotenberg.
+const (
+	HealthUp   = "up"
+	HealthDown = "down"
+)
+
 // HealthResponse represents the response from the health check endpoint.
 type HealthResponse struct {
-	Status  string         `json:"status"`
-	Details map[string]any `json:"details"`
+	Status  string                  `json:"status"`
+	Details map[string]ModuleHealth `json:"details"`
+}
+
+// ModuleHealth is the health of a Gotenberg module, such as chromium or libreoffice.
+type ModuleHealth struct {
+	Status    string    `json:"status"`
+	Timestamp time.Time `json:"timestamp"`
+	Error     string    `json:"error,omitempty"`
+}
+
+// Up reports whether the given modules are up, or the whole service if no module is given.
+// A module missing from the response is not up.
+func (h *HealthResponse) Up(modules ...string) bool {
+	if len(modules) == 0 {
+		return h.Status == HealthUp
+	}
+	for _, m := range modules {
+		if h.Details[m].Status != HealthUp {
+			return false
+		}
+	}
+	return true
+}
+
+// notUp describes why the given modules, or the whole service, are not up.
+func (h *HealthResponse) notUp(modules []string) error {
+	if len(modules) == 0 {
+		return fmt.Errorf("service is %s", h.Status)
+	}
+	var reasons []string
+	for _, m := range modules {
+		switch mh, ok := h.Details[m]; {
+		case !ok:
+			reasons = append(reasons, m+" is not reported")
+		case mh.Status != HealthUp && mh.Error != "":
+			reasons = append(reasons, fmt.Sprintf("%s is %s: %s", m, mh.Status, mh.Error))
+		case mh.Status != HealthUp:
+			reasons = append(reasons, fmt.Sprintf("%s is %s", m, mh.Status))
+		}
+	}
+	return errors.New(strings.Join(reasons, "; "))
 }
 
 // getBytes performs a HTTP GET request to the specified path and returns the response body bytes.
@@ -42,6 +92,31 @@ func (c *Client) GetHealth(ctx context.Context) (*HealthResponse, error) {
 	return &healthResp, nil
 }
 
+// WaitUntilHealthy polls the health of the service until the given modules, or the whole service
+// if no module is given, are up. Polls back off exponentially from 100ms to 2s.
+// It returns the context error, along with the last failure, if the service is not healthy in time.
+func (c *Client) WaitUntilHealthy(ctx context.Context, modules ...string) error {
+	delay := 100 * time.Millisecond
+	for {
+		health, err := c.GetHealth(ctx)
+		switch {
+		case err == nil && health.Up(modules...):
+			return nil
+		case err == nil:
+			err = health.notUp(modules)
+		}
+
+		timer := time.NewTimer(delay)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return fmt.Errorf("gotenberg: not healthy: %w (last check: %v)", ctx.Err(), err)
+		case <-timer.C:
+		}
+		delay = min(2*delay, 2*time.Second)
+	}
+}
+
 // GetVersion returns the Gotenberg service version.
 func (c *Client) GetVersion(ctx context.Context) (string, error) {
 	b, err := getBytes(ctx, c.HttpStream, "/version")
@@ -0,0 +1,119 @@
+package gotenberg
+
+import (
+	"context"
+	"slices"
+	"sync"
+	"time"
+)
+
+// HealthUnreachable is the status a HealthWatcher reports for a service whose health cannot be queried.
+const HealthUnreachable = "unreachable"
+
+// HealthTransition is a change of the status of the service or of one of its modules.
+type HealthTransition struct {
+	// Module is the module whose status changed, or empty for the whole service.
+	Module string
+	// From is the previous status, empty for the first status observed.
+	From string
+	To   string
+	// Error is the error reported by the module, or the failure to query an unreachable service.
+	Error string
+	Time  time.Time
+}
+
+// HealthWatcher polls the health of a service and reports the transitions of its status
+// and of the statuses of its modules, for dashboards or load shedding.
+type HealthWatcher struct {
+	client      *Client
+	interval    time.Duration
+	transitions chan HealthTransition
+
+	mu       sync.Mutex
+	statuses map[string]string
+}
+
+// WatchHealth polls the health of the service every interval until the context is done.
+func (c *Client) WatchHealth(ctx context.Context, interval time.Duration) *HealthWatcher {
+	w := &HealthWatcher{
+		client:      c,
+		interval:    interval,
+		transitions: make(chan HealthTransition, 16),
+		statuses:    make(map[string]string),
+	}
+	go w.run(ctx)
+	return w
+}
+
+// Transitions returns the channel transitions are sent on, closed once the context is done.
+// Polling pauses while the channel is full.
+func (w *HealthWatcher) Transitions() <-chan HealthTransition {
+	return w.transitions
+}
+
+// Status returns the last status observed for a module, or for the whole service if module is empty.
+func (w *HealthWatcher) Status(module string) string {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return w.statuses[module]
+}
+
+// run polls the health until the context is done.
+func (w *HealthWatcher) run(ctx context.Context) {
+	defer close(w.transitions)
+
+	ticker := time.NewTicker(w.interval)
+	defer ticker.Stop()
+	for {
+		if !w.poll(ctx) {
+			return
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// poll queries the health and sends the transitions; it returns false once the context is done.
+func (w *HealthWatcher) poll(ctx context.Context) bool {
+	observed := make(map[string]ModuleHealth)
+	health, err := w.client.GetHealth(ctx)
+	if ctx.Err() != nil {
+		return false
+	}
+	if err != nil {
+		observed[""] = ModuleHealth{Status: HealthUnreachable, Error: err.Error()}
+	} else {
+		observed[""] = ModuleHealth{Status: health.Status}
+		for m, mh := range health.Details {
+			observed[m] = mh
+		}
+	}
+
+	modules := make([]string, 0, len(observed))
+	for m := range observed {
+		modules = append(modules, m)
+	}
+	slices.Sort(modules)
+
+	now := time.Now()
+	for _, m := range modules {
+		mh := observed[m]
+		w.mu.Lock()
+		from := w.statuses[m]
+		w.statuses[m] = mh.Status
+		w.mu.Unlock()
+		if from == mh.Status {
+			continue
+		}
+
+		select {
+		case w.transitions <- HealthTransition{Module: m, From: from, To: mh.Status, Error: mh.Error, Time: now}:
+		case <-ctx.Done():
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,270 @@
+package gotenberg
+
+import (
+	"context"
+	"log/slog"
+	"net/http"
+	"slices"
+	"time"
+)
+
+// Hooks observe the requests of a client, with the context and the builder-level description
+// of each request. They are called on the goroutine calling Send, except OnSend, called on the
+// goroutine sending the request.
+type Hooks interface {
+	// OnBuild is called once the request is built and validated, before it is sent or replayed
+	// from the cache.
+	OnBuild(ctx context.Context, info RequestInfo)
+	// OnSend is called when the request is uploaded to Gotenberg, after any throttling.
+	OnSend(ctx context.Context, info RequestInfo)
+	// OnResponse is called with the response of the request, whatever its status.
+	OnResponse(ctx context.Context, info RequestInfo, resp *Response)
+	// OnError is called when Send fails.
+	OnError(ctx context.Context, info RequestInfo, err error)
+	// OnRetry is called before the request is sent again after the given failed attempt,
+	// when the identical request of another caller it was sharing timed out.
+	OnRetry(ctx context.Context, info RequestInfo, attempt int, err error)
+}
+
+// NopHooks implements Hooks with methods doing nothing, for embedding in hooks
+// observing only some events.
+type NopHooks struct{}
+
+// OnBuild implements the Hooks interface.
+func (NopHooks) OnBuild(context.Context, RequestInfo) {}
+
+// OnSend implements the Hooks interface.
+func (NopHooks) OnSend(context.Context, RequestInfo) {}
+
+// OnResponse implements the Hooks interface.
+func (NopHooks) OnResponse(context.Context, RequestInfo, *Response) {}
+
+// OnError implements the Hooks interface.
+func (NopHooks) OnError(context.Context, RequestInfo, error) {}
+
+// OnRetry implements the Hooks interface.
+func (NopHooks) OnRetry(context.Context, RequestInfo, int, error) {}
+
+// RequestInfo describes a request to hooks.
+type RequestInfo struct {
+	// Module is the Gotenberg module handling the request: chromium, libreoffice or pdfengines.
+	Module string
+	Route  string
+	Trace  string
+	// Options are the form fields of the request, passwords redacted.
+	Options map[string]string
+	// Files are the names of the files to upload.
+	Files []string
+	// Start is the time Send was called.
+	Start time.Time
+}
+
+// LogValue implements the slog.LogValuer interface.
+func (i RequestInfo) LogValue() slog.Value {
+	keys := make([]string, 0, len(i.Options))
+	for k := range i.Options {
+		keys = append(keys, k)
+	}
+	slices.Sort(keys)
+	options := make([]slog.Attr, 0, len(keys))
+	for _, k := range keys {
+		options = append(options, slog.String(k, i.Options[k]))
+	}
+	return slog.GroupValue(
+		slog.String("module", i.Module),
+		slog.String("route", i.Route),
+		slog.String("trace", i.Trace),
+		slog.Attr{Key: "options", Value: slog.GroupValue(options...)},
+		slog.Any("files", i.Files),
+	)
+}
+
+// Hooks adds hooks called for every request of the client, in order.
+func (c *Client) Hooks(hooks ...Hooks) *Client {
+	c.hooks = append(c.hooks, hooks...)
+	return c
+}
+
+// instrumentation calls the hooks of a client for a request. Its methods do nothing on a nil receiver.
+type instrumentation struct {
+	hooks []Hooks
+	ctx   context.Context
+	info  RequestInfo
+	built bool
+}
+
+// instrument returns the instrumentation of the request, nil if its client has no hooks.
+func (r *Request) instrument() *instrumentation {
+	if r.client == nil || len(r.client.hooks) == 0 {
+		return nil
+	}
+	ctx := r.ctx
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	return &instrumentation{hooks: r.client.hooks, ctx: ctx, info: RequestInfo{Start: time.Now()}}
+}
+
+// describe records the description of the request as it is built.
+func (in *instrumentation) describe(r *Request) {
+	in.info.Module = routeModule(r.route)
+	in.info.Route = r.route
+	in.info.Trace = r.headers.Get("Gotenberg-Trace")
+	in.info.Options = make(map[string]string, len(r.params))
+	for _, p := range r.params {
+		value := p.value
+		if sensitiveParams[p.key] {
+			value = secret(value).String()
+		}
+		in.info.Options[p.key] = value
+	}
+	in.info.Files = make([]string, 0, len(r.files))
+	for _, f := range r.uploads() {
+		in.info.Files = append(in.info.Files, f.Name)
+	}
+}
+
+// build calls OnBuild once the request is built.
+func (in *instrumentation) build(r *Request) {
+	if in == nil {
+		return
+	}
+	in.describe(r)
+	in.built = true
+	for _, h := range in.hooks {
+		h.OnBuild(in.ctx, in.info)
+	}
+}
+
+// send calls OnSend.
+func (in *instrumentation) send() {
+	if in == nil {
+		return
+	}
+	for _, h := range in.hooks {
+		h.OnSend(in.ctx, in.info)
+	}
+}
+
+// retry calls OnRetry.
+func (in *instrumentation) retry(attempt int, err error) {
+	if in == nil {
+		return
+	}
+	for _, h := range in.hooks {
+		h.OnRetry(in.ctx, in.info, attempt, err)
+	}
+}
+
+// done calls OnResponse or OnError with the outcome of Send.
+func (in *instrumentation) done(r *Request, resp *Response, err error) {
+	if in == nil {
+		return
+	}
+	if !in.built {
+		// The request failed validation.
+		in.describe(r)
+	}
+	for _, h := range in.hooks {
+		if err != nil {
+			h.OnError(in.ctx, in.info, err)
+		} else {
+			h.OnResponse(in.ctx, in.info, resp)
+		}
+	}
+}
+
+// SlogHooks are hooks logging requests with a slog.Logger: builds and sends at debug level,
+// responses at info level, or warn level for non-successful statuses, retries at warn level
+// and failures at error level.
+type SlogHooks struct {
+	Logger *slog.Logger
+}
+
+// NewSlogHooks returns hooks logging with the given logger, or the default logger if nil.
+func NewSlogHooks(logger *slog.Logger) *SlogHooks {
+	if logger == nil {
+		logger = slog.Default()
+	}
+	return &SlogHooks{Logger: logger}
+}
+
+// OnBuild implements the Hooks interface.
+func (h *SlogHooks) OnBuild(ctx context.Context, info RequestInfo) {
+	h.Logger.LogAttrs(ctx, slog.LevelDebug, "gotenberg request built", slog.Any("request", info))
+}
+
+// OnSend implements the Hooks interface.
+func (h *SlogHooks) OnSend(ctx context.Context, info RequestInfo) {
+	h.Logger.LogAttrs(ctx, slog.LevelDebug, "gotenberg request sent", slog.Any("request", info))
+}
+
+// OnResponse implements the Hooks interface.
+func (h *SlogHooks) OnResponse(ctx context.Context, info RequestInfo, resp *Response) {
+	// The body belongs to the caller: the level follows the status code only.
+	level := slog.LevelInfo
+	if resp.StatusCode < http.StatusOK || resp.StatusCode >= http.StatusMultipleChoices {
+		level = slog.LevelWarn
+	}
+	h.Logger.LogAttrs(ctx, level, "gotenberg response",
+		slog.Any("request", info),
+		slog.Int("status", resp.StatusCode),
+		slog.Bool("cached", resp.Cached),
+		slog.Bool("shared", resp.Shared),
+		slog.Duration("elapsed", time.Since(info.Start)),
+	)
+}
+
+// OnError implements the Hooks interface.
+func (h *SlogHooks) OnError(ctx context.Context, info RequestInfo, err error) {
+	h.Logger.LogAttrs(ctx, slog.LevelError, "gotenberg request failed",
+		slog.Any("request", info),
+		slog.Any("error", err),
+		slog.Duration("elapsed", time.Since(info.Start)),
+	)
+}
+
+// OnRetry implements the Hooks interface.
+func (h *SlogHooks) OnRetry(ctx context.Context, info RequestInfo, attempt int, err error) {
+	h.Logger.LogAttrs(ctx, slog.LevelWarn, "gotenberg request retried",
+		slog.Any("request", info),
+		slog.Int("attempt", attempt),
+		slog.Any("error", err),
+	)
+}
+
+// traceKey is the context key of trace identifiers.
+type traceKey struct{}
+
+// ContextWithTrace returns a context carrying a trace identifier, sent as the Gotenberg-Trace
+// header of the requests using it that have no trace set with Trace.
+func ContextWithTrace(ctx context.Context, trace string) context.Context {
+	return context.WithValue(ctx, traceKey{}, trace)
+}
+
+// TraceFromContext returns the trace identifier carried by the context, empty if none.
+func TraceFromContext(ctx context.Context) string {
+	trace, _ := ctx.Value(traceKey{}).(string)
+	return trace
+}
+
+// TraceExtractor sets the function extracting trace identifiers from the context of requests,
+// such as the trace ID of an OpenTelemetry span, in place of TraceFromContext.
+func (c *Client) TraceExtractor(extract func(ctx context.Context) string) *Client {
+	c.traceExtractor = extract
+	return c
+}
+
+// propagateTrace sets the Gotenberg-Trace header from the context of the request, unless set.
+func (r *Request) propagateTrace() {
+	if r.ctx == nil || r.headers.Get("Gotenberg-Trace") != "" {
+		return
+	}
+	extract := TraceFromContext
+	if r.client != nil && r.client.traceExtractor != nil {
+		extract = r.client.traceExtractor
+	}
+	if trace := extract(r.ctx); trace != "" {
+		r.Trace(trace)
+	}
+}
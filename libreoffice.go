@@ -2,6 +2,7 @@ package gotenberg
 
 import (
 	"context"
+	"errors"
 	"io"
 	"strconv"
 	"time"
@@ -9,15 +10,45 @@ import (
 
 // Convert creates a request to convert Office documents to PDF.
 func (r *LibreOffice) Convert(ctx context.Context) *LibreOffice {
-	r.Req = r.HttpStream.Multipart(ctx, "/forms/libreoffice/convert")
+	r.open(ctx, "/forms/libreoffice/convert")
 	return r
 }
 
 // Send executes the conversion request and returns the response.
+// Unless disabled with FormatDetection, file formats are checked first: missing or incorrect
+// extensions are corrected and unsupported files are reported as an *UnsupportedFormatError.
+//
+// Files requiring different passwords are converted with a request per password. Merged
+// conversions are then merged in call order, with PDF/A, PDF/UA, metadata and flattening
+// applied to the merged PDF; other conversions are returned in a single ZIP archive holding
+// a PDF per file, named after it. Such conversions cannot use webhooks, nor be merged and split.
 func (r *LibreOffice) Send() (*Response, error) {
+	if !r.skipFormatDetection {
+		if err := r.detectFormats(); err != nil {
+			return nil, err
+		}
+	}
+
+	password := r.password
+	switch groups := r.passwordGroups(r.files); len(groups) {
+	case 0:
+	case 1:
+		password = groups[0].password
+	default:
+		return r.sendProtected()
+	}
+	if password != "" {
+		r.Param("password", string(password))
+	}
 	return r.Request.Send()
 }
 
+// FormatDetection specifies whether file formats are checked before sending. Enabled by default.
+func (r *LibreOffice) FormatDetection(enabled bool) *LibreOffice {
+	r.skipFormatDetection = !enabled
+	return r
+}
+
 // Header adds an HTTP header to the conversion request.
 func (r *LibreOffice) Header(key, value string) *LibreOffice {
 	r.Request.Header(key, value)
@@ -48,6 +79,12 @@ func (r *LibreOffice) File(filename string, content io.Reader) *LibreOffice {
 	return r
 }
 
+// FileSource adds a file provided by a FileSource to the conversion request.
+func (r *LibreOffice) FileSource(src FileSource) *LibreOffice {
+	r.Request.FileSource(src)
+	return r
+}
+
 // WebhookURL sets the webhook URL and HTTP method for successful conversions.
 func (r *LibreOffice) WebhookURL(url, method string) *LibreOffice {
 	r.Request.WebhookURL(url, method)
@@ -90,6 +127,18 @@ func (r *LibreOffice) Trace(trace string) *LibreOffice {
 	return r
 }
 
+// NoCache bypasses the cache of the client for the request.
+func (r *LibreOffice) NoCache() *LibreOffice {
+	r.Request.NoCache()
+	return r
+}
+
+// Progress sets a function reporting the upload of the files and the download of the response.
+func (r *LibreOffice) Progress(fn func(Progress)) *LibreOffice {
+	r.Request.Progress(fn)
+	return r
+}
+
 // Timeout sets a timeout for the request.
 func (r *LibreOffice) Timeout(duration time.Duration) *LibreOffice {
 	r.Request.Timeout(duration)
@@ -102,9 +151,10 @@ func (r *LibreOffice) Metadata(key, value string) *LibreOffice {
 	return r
 }
 
-// Password sets the password for opening the source file.
-func (r *LibreOffice) Password(password string) *LibreOffice {
-	return r.Param("password", password)
+// PDFMetadata sets typed metadata for the operation, preserving value types.
+func (r *LibreOffice) PDFMetadata(m PDFMetadata) *LibreOffice {
+	r.Request.PDFMetadata(m)
+	return r
 }
 
 // Landscape sets the paper orientation to landscape.
@@ -112,9 +162,13 @@ func (r *LibreOffice) Landscape(landscape bool) *LibreOffice {
 	return r.Bool("landscape", landscape)
 }
 
-// NativePageRanges sets the page ranges to print.
+// NativePageRanges sets the page ranges to print, e.g., '1-5, 8, 11-13'.
+// The selection is validated and normalized; see PageRanges.
 func (r *LibreOffice) NativePageRanges(ranges string) *LibreOffice {
-	return r.Param("nativePageRanges", ranges)
+	if ranges, ok := r.pageRanges("nativePageRanges", ranges, true); ok {
+		r.Param("nativePageRanges", ranges)
+	}
+	return r
 }
 
 // UpdateIndexes specifies whether to update the indexes before conversion.
@@ -217,8 +271,9 @@ func (r *LibreOffice) MaxImageResolution(resolution int) *LibreOffice {
 	return r.Param("maxImageResolution", strconv.Itoa(resolution))
 }
 
-// Merge merges the resulting PDFs alphanumerically.
+// Merge merges the resulting PDFs in the order the files were added.
 func (r *LibreOffice) Merge(merge bool) *LibreOffice {
+	r.ordered = merge
 	return r.Bool("merge", merge)
 }
 
@@ -227,9 +282,13 @@ func (r *LibreOffice) SplitMode(mode string) *LibreOffice {
 	return r.Param("splitMode", mode)
 }
 
-// SplitSpan sets the split span.
+// SplitSpan sets the split span: a page count in intervals mode, or page ranges in pages mode.
+// The span is validated; see PageRanges. The pdfcpu syntax, e.g. 'even' or 'l-2-', is accepted too.
 func (r *LibreOffice) SplitSpan(span string) *LibreOffice {
-	return r.Param("splitSpan", span)
+	if span, ok := r.pageSelection("splitSpan", span, false); ok {
+		r.Param("splitSpan", span)
+	}
+	return r
 }
 
 // SplitUnify specifies whether to unify split pages.
@@ -248,33 +307,49 @@ func (r *LibreOffice) PDFUA(pdfua bool) *LibreOffice {
 }
 
 // NativeWatermarkText sets the text for LibreOffice's built-in watermark.
+// It cannot be combined with a tiled watermark.
 func (r *LibreOffice) NativeWatermarkText(text string) *LibreOffice {
-	return r.Param("nativeWatermarkText", text)
+	if r.has("nativeTiledWatermarkText") {
+		r.fail(errors.New("native watermark: tiled and single watermarks are mutually exclusive"))
+		return r
+	}
+	r.paramSince("nativeWatermarkText", text, Version{8, 27, 0})
+	return r
 }
 
 // NativeWatermarkColor sets the color of the native watermark.
 func (r *LibreOffice) NativeWatermarkColor(color string) *LibreOffice {
-	return r.Param("nativeWatermarkColor", color)
+	r.paramSince("nativeWatermarkColor", color, Version{8, 27, 0})
+	return r
 }
 
 // NativeWatermarkFontHeight sets the font height of the native watermark in points.
 func (r *LibreOffice) NativeWatermarkFontHeight(height int) *LibreOffice {
-	return r.Param("nativeWatermarkFontHeight", strconv.Itoa(height))
+	r.paramSince("nativeWatermarkFontHeight", strconv.Itoa(height), Version{8, 27, 0})
+	return r
 }
 
 // NativeWatermarkRotateAngle sets the rotation angle of the native watermark in degrees.
 func (r *LibreOffice) NativeWatermarkRotateAngle(angle int) *LibreOffice {
-	return r.Param("nativeWatermarkRotateAngle", strconv.Itoa(angle))
+	r.paramSince("nativeWatermarkRotateAngle", strconv.Itoa(angle), Version{8, 27, 0})
+	return r
 }
 
 // NativeWatermarkFontName sets the font name for the native watermark.
 func (r *LibreOffice) NativeWatermarkFontName(name string) *LibreOffice {
-	return r.Param("nativeWatermarkFontName", name)
+	r.paramSince("nativeWatermarkFontName", name, Version{8, 27, 0})
+	return r
 }
 
 // NativeTiledWatermarkText sets a tiled watermark text using LibreOffice's built-in rendering.
+// It cannot be combined with a single watermark.
 func (r *LibreOffice) NativeTiledWatermarkText(text string) *LibreOffice {
-	return r.Param("nativeTiledWatermarkText", text)
+	if r.has("nativeWatermarkText") {
+		r.fail(errors.New("native watermark: tiled and single watermarks are mutually exclusive"))
+		return r
+	}
+	r.paramSince("nativeTiledWatermarkText", text, Version{8, 27, 0})
+	return r
 }
 
 // InitialView sets the initial view when the PDF is opened.
@@ -0,0 +1,75 @@
+package gotenberg
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+)
+
+// MergedFile associates a file taking part in an ordered merge with the name it is uploaded under.
+type MergedFile struct {
+	Name     string
+	Uploaded string
+}
+
+// MergeOrder returns the files to merge in call order, along with their upload names.
+// Upload names only differ from the original names for ordered merges.
+func (r *Request) MergeOrder() []MergedFile {
+	var order []MergedFile
+	for _, f := range r.files {
+		if f.field == "files" {
+			order = append(order, MergedFile{Name: f.Name, Uploaded: f.Name})
+		}
+	}
+	if !r.ordered {
+		return order
+	}
+
+	// Gotenberg merges files in alphanumeric order: zero-padded indices of equal width
+	// make this order match the call order.
+	width := len(strconv.Itoa(max(len(order)-1, 0)))
+	for i := range order {
+		order[i].Uploaded = fmt.Sprintf("%0*d_%s", width, i, order[i].Name)
+	}
+	return order
+}
+
+// uploads returns the files to attach to the multipart body, renamed according to MergeOrder.
+func (r *Request) uploads() []formFile {
+	if !r.ordered {
+		return r.files
+	}
+
+	order := r.MergeOrder()
+	files := make([]formFile, len(r.files))
+	i := 0
+	for j, f := range r.files {
+		files[j] = f
+		if f.field == "files" {
+			files[j].Name = order[i].Uploaded
+			i++
+		}
+	}
+	return files
+}
+
+// OrderedMerge creates a request to merge PDFs in the order they are given, rather than
+// in alphanumeric filename order. Files added later with File are appended to that order.
+func (r *PDFEngines) OrderedMerge(ctx context.Context, parts ...NamedFile) *PDFEngines {
+	r.Merge(ctx)
+	r.ordered = true
+	for _, p := range parts {
+		r.File(p.Name, p.Content)
+	}
+	return r
+}
+
+// MergeOrder returns the files to merge in call order, along with their upload names.
+func (r *PDFEngines) MergeOrder() []MergedFile {
+	return r.Request.MergeOrder()
+}
+
+// MergeOrder returns the files to merge in call order, along with their upload names.
+func (r *LibreOffice) MergeOrder() []MergedFile {
+	return r.Request.MergeOrder()
+}
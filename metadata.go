@@ -0,0 +1,224 @@
+package gotenberg
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Trapped represents the trapping state of a PDF document.
+type Trapped string
+
+// Trapping states of a PDF document.
+const (
+	TrappedTrue    Trapped = "True"
+	TrappedFalse   Trapped = "False"
+	TrappedUnknown Trapped = "Unknown"
+)
+
+// PDFMetadata represents the well-known XMP/Info metadata of a PDF document.
+// Zero-valued fields are left untouched when writing. Other entries are kept in Extra.
+type PDFMetadata struct {
+	Author       string
+	Copyright    string
+	CreationDate time.Time
+	Creator      string
+	Keywords     []string
+	Marked       *bool
+	ModDate      time.Time
+	PDFVersion   float64
+	Producer     string
+	Subject      string
+	Title        string
+	Trapped      Trapped
+	Extra        map[string]any
+}
+
+// metadataDateLayouts are the date layouts accepted when decoding metadata.
+// Gotenberg reports dates in the ExifTool format.
+var metadataDateLayouts = []string{
+	time.RFC3339,
+	"2006:01:02 15:04:05Z07:00",
+	"2006:01:02 15:04:05",
+}
+
+// Map returns the metadata as a map suitable for the metadata form field,
+// with arrays, dates and booleans kept as such.
+func (m PDFMetadata) Map() map[string]any {
+	out := make(map[string]any, len(m.Extra)+12)
+	for k, v := range m.Extra {
+		out[k] = v
+	}
+	for k, v := range map[string]string{
+		"Author":    m.Author,
+		"Copyright": m.Copyright,
+		"Creator":   m.Creator,
+		"Producer":  m.Producer,
+		"Subject":   m.Subject,
+		"Title":     m.Title,
+		"Trapped":   string(m.Trapped),
+	} {
+		if v != "" {
+			out[k] = v
+		}
+	}
+	if !m.CreationDate.IsZero() {
+		out["CreationDate"] = m.CreationDate.Format(time.RFC3339)
+	}
+	if !m.ModDate.IsZero() {
+		out["ModDate"] = m.ModDate.Format(time.RFC3339)
+	}
+	if len(m.Keywords) > 0 {
+		out["Keywords"] = m.Keywords
+	}
+	if m.Marked != nil {
+		out["Marked"] = *m.Marked
+	}
+	if m.PDFVersion != 0 {
+		out["PDFVersion"] = m.PDFVersion
+	}
+	return out
+}
+
+// MarshalJSON implements the json.Marshaler interface.
+func (m PDFMetadata) MarshalJSON() ([]byte, error) {
+	return json.Marshal(m.Map())
+}
+
+// UnmarshalJSON implements the json.Unmarshaler interface.
+// Unknown entries, and dates, Marked and PDFVersion values that cannot be parsed, are stored in Extra.
+func (m *PDFMetadata) UnmarshalJSON(b []byte) error {
+	var raw map[string]any
+	if err := json.Unmarshal(b, &raw); err != nil {
+		return err
+	}
+
+	*m = PDFMetadata{}
+	for k, v := range raw {
+		var err error
+		switch k {
+		case "Author":
+			m.Author = metadataString(v)
+		case "Copyright":
+			m.Copyright = metadataString(v)
+		case "Creator":
+			m.Creator = metadataString(v)
+		case "Producer":
+			m.Producer = metadataString(v)
+		case "Subject":
+			m.Subject = metadataString(v)
+		case "Title":
+			m.Title = metadataString(v)
+		case "Trapped":
+			m.Trapped = Trapped(metadataString(v))
+		case "CreationDate", "CreateDate":
+			m.CreationDate, err = metadataTime(v)
+		case "ModDate", "ModifyDate":
+			m.ModDate, err = metadataTime(v)
+		case "Keywords":
+			m.Keywords = metadataKeywords(v)
+		case "Marked":
+			var marked bool
+			if marked, err = strconv.ParseBool(metadataString(v)); err == nil {
+				m.Marked = &marked
+			}
+		case "PDFVersion":
+			m.PDFVersion, err = strconv.ParseFloat(metadataString(v), 64)
+		default:
+			m.extra(k, v)
+		}
+		if err != nil {
+			// A value of an unexpected form is kept as is rather than failing every file.
+			m.extra(k, v)
+		}
+	}
+	return nil
+}
+
+// extra stores an entry in Extra.
+func (m *PDFMetadata) extra(key string, value any) {
+	if m.Extra == nil {
+		m.Extra = make(map[string]any)
+	}
+	m.Extra[key] = value
+}
+
+// metadataString formats a decoded JSON value as a string.
+func metadataString(v any) string {
+	switch v := v.(type) {
+	case nil:
+		return ""
+	case string:
+		return v
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64)
+	default:
+		return fmt.Sprint(v)
+	}
+}
+
+// metadataTime parses a decoded JSON value as a date.
+func metadataTime(v any) (time.Time, error) {
+	s := metadataString(v)
+	if s == "" {
+		return time.Time{}, nil
+	}
+	var err error
+	for _, layout := range metadataDateLayouts {
+		var t time.Time
+		if t, err = time.Parse(layout, s); err == nil {
+			return t, nil
+		}
+	}
+	return time.Time{}, err
+}
+
+// metadataKeywords decodes keywords given either as an array or as a comma-separated string.
+func metadataKeywords(v any) []string {
+	var keywords []string
+	switch v := v.(type) {
+	case []any:
+		for _, k := range v {
+			keywords = append(keywords, metadataString(k))
+		}
+	default:
+		for _, k := range strings.Split(metadataString(v), ",") {
+			if k = strings.TrimSpace(k); k != "" {
+				keywords = append(keywords, k)
+			}
+		}
+	}
+	return keywords
+}
+
+// ReadMetadata reads the metadata of the given PDFs.
+// The result is keyed by filename.
+func (r *PDFEngines) ReadMetadata(ctx context.Context, files ...NamedFile) (map[string]PDFMetadata, error) {
+	r.MetadataRead(ctx)
+	for _, f := range files {
+		r.File(f.Name, f.Content)
+	}
+
+	resp, err := r.Send()
+	if err != nil {
+		return nil, err
+	}
+
+	var metadata map[string]PDFMetadata
+	if err := resp.decodeJSON(&metadata); err != nil {
+		return nil, err
+	}
+	return metadata, nil
+}
+
+// WriteMetadata writes the metadata to the given PDFs.
+func (r *PDFEngines) WriteMetadata(ctx context.Context, m PDFMetadata, files ...NamedFile) (*Response, error) {
+	r.MetadataWrite(ctx).PDFMetadata(m)
+	for _, f := range files {
+		r.File(f.Name, f.Content)
+	}
+	return r.Send()
+}
@@ -0,0 +1,395 @@
+package gotenberg
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"io"
+	"math"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/nativebpm/httpstream"
+)
+
+// MetricType is the type of a Prometheus metric family.
+type MetricType string
+
+// Metric types of the Prometheus text format.
+const (
+	MetricCounter   MetricType = "counter"
+	MetricGauge     MetricType = "gauge"
+	MetricHistogram MetricType = "histogram"
+	MetricSummary   MetricType = "summary"
+	MetricUntyped   MetricType = "untyped"
+)
+
+// MetricFamily is a set of metrics sharing a name, a type and a help text.
+type MetricFamily struct {
+	Name    string
+	Help    string
+	Type    MetricType
+	Metrics []Metric
+}
+
+// Metric is a metric of a family, identified by its labels.
+// Counters, gauges and untyped metrics have a Value; histograms have Buckets, a Sum and a Count;
+// summaries have Quantiles, a Sum and a Count.
+type Metric struct {
+	Labels    map[string]string
+	Value     float64
+	Sum       float64
+	Count     float64
+	Buckets   []Bucket
+	Quantiles []Quantile
+	// Timestamp is the time of the sample, zero if the exposition does not set it.
+	Timestamp time.Time
+}
+
+// Bucket is a cumulative histogram bucket: the number of observations less than or equal to UpperBound.
+type Bucket struct {
+	UpperBound float64
+	Count      float64
+}
+
+// Quantile is a quantile of a summary.
+type Quantile struct {
+	Quantile float64
+	Value    float64
+}
+
+// Metrics are the metric families of an exposition, by name.
+type Metrics map[string]*MetricFamily
+
+// GetMetricFamilies returns the Prometheus metrics of the Gotenberg service, parsed.
+func (c *Client) GetMetricFamilies(ctx context.Context) (Metrics, error) {
+	resp, err := c.HttpStream.Request(ctx, httpstream.GET, "/prometheus/metrics").Send()
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if err := (&Response{Response: resp}).checkStatus(); err != nil {
+		return nil, err
+	}
+	return ParseMetrics(resp.Body)
+}
+
+// ParseMetrics parses metrics in the Prometheus text exposition format.
+func ParseMetrics(r io.Reader) (Metrics, error) {
+	p := &metricsParser{families: make(Metrics), index: make(map[string]int)}
+	s := bufio.NewScanner(r)
+	s.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+	for n := 1; s.Scan(); n++ {
+		if err := p.line(strings.TrimSpace(s.Text())); err != nil {
+			return nil, fmt.Errorf("metrics line %d: %w", n, err)
+		}
+	}
+	if err := s.Err(); err != nil {
+		return nil, err
+	}
+	return p.families, nil
+}
+
+// Value returns the value of a counter, gauge or untyped family, summed over its label sets,
+// and false if the family does not exist.
+func (m Metrics) Value(name string) (float64, bool) {
+	f, ok := m[name]
+	if !ok {
+		return 0, false
+	}
+	var sum float64
+	for _, metric := range f.Metrics {
+		sum += metric.Value
+	}
+	return sum, true
+}
+
+// gotenberg returns the value of a Gotenberg metric from its name without namespace, such as
+// "chromium_requests_queue_size". The default gotenberg namespace is preferred, but any
+// namespace set with --prometheus-namespace is accepted.
+func (m Metrics) gotenberg(name string) (float64, bool) {
+	if v, ok := m.Value("gotenberg_" + name); ok {
+		return v, true
+	}
+	names := make([]string, 0, len(m))
+	for n := range m {
+		names = append(names, n)
+	}
+	slices.Sort(names)
+	for _, n := range names {
+		if strings.HasSuffix(n, "_"+name) {
+			return m.Value(n)
+		}
+	}
+	return 0, false
+}
+
+// ChromiumQueueSize returns the number of requests waiting for Chromium.
+func (m Metrics) ChromiumQueueSize() (float64, bool) {
+	return m.gotenberg("chromium_requests_queue_size")
+}
+
+// LibreOfficeQueueSize returns the number of requests waiting for LibreOffice.
+func (m Metrics) LibreOfficeQueueSize() (float64, bool) {
+	return m.gotenberg("libreoffice_requests_queue_size")
+}
+
+// ChromiumActiveInstances returns the number of running Chromium instances,
+// for Gotenberg versions reporting it.
+func (m Metrics) ChromiumActiveInstances() (float64, bool) {
+	return m.gotenberg("chromium_active_instances_count")
+}
+
+// LibreOfficeActiveInstances returns the number of running LibreOffice instances,
+// for Gotenberg versions reporting it.
+func (m Metrics) LibreOfficeActiveInstances() (float64, bool) {
+	return m.gotenberg("libreoffice_active_instances_count")
+}
+
+// ChromiumRestarts returns the number of Chromium restarts.
+func (m Metrics) ChromiumRestarts() (float64, bool) {
+	return m.gotenberg("chromium_restarts_count")
+}
+
+// LibreOfficeRestarts returns the number of LibreOffice restarts.
+func (m Metrics) LibreOfficeRestarts() (float64, bool) {
+	return m.gotenberg("libreoffice_restarts_count")
+}
+
+// metricsParser accumulates the families of an exposition.
+type metricsParser struct {
+	families Metrics
+	// index locates the metric of a family with a given label set, by family name and labels.
+	index map[string]int
+}
+
+// family returns the family with the given name, creating it if needed.
+func (p *metricsParser) family(name string) *MetricFamily {
+	f, ok := p.families[name]
+	if !ok {
+		f = &MetricFamily{Name: name, Type: MetricUntyped}
+		p.families[name] = f
+	}
+	return f
+}
+
+// line parses a line of the exposition.
+func (p *metricsParser) line(line string) error {
+	if line == "" {
+		return nil
+	}
+	if comment, ok := strings.CutPrefix(line, "#"); ok {
+		fields := strings.Fields(comment)
+		if len(fields) < 2 || fields[0] != "HELP" && fields[0] != "TYPE" {
+			return nil
+		}
+		f := p.family(fields[1])
+		if fields[0] == "HELP" {
+			help := strings.TrimLeft(strings.TrimLeft(comment, " \t")[len("HELP"):], " \t")
+			f.Help = unescapeMetric(strings.TrimLeft(help[len(fields[1]):], " \t"), false)
+			return nil
+		}
+		if len(fields) != 3 {
+			return fmt.Errorf("invalid TYPE comment %q", line)
+		}
+		switch t := MetricType(fields[2]); t {
+		case MetricCounter, MetricGauge, MetricHistogram, MetricSummary, MetricUntyped:
+			f.Type = t
+		default:
+			return fmt.Errorf("unknown metric type %q", fields[2])
+		}
+		return nil
+	}
+	return p.sample(line)
+}
+
+// sample parses a sample line and adds it to its family.
+func (p *metricsParser) sample(line string) error {
+	end := strings.IndexAny(line, "{ \t")
+	if end <= 0 {
+		return fmt.Errorf("invalid sample %q", line)
+	}
+	name, rest := line[:end], line[end:]
+
+	labels := make(map[string]string)
+	if strings.HasPrefix(rest, "{") {
+		var err error
+		if labels, rest, err = parseLabels(rest[1:]); err != nil {
+			return err
+		}
+	}
+
+	fields := strings.Fields(rest)
+	if len(fields) < 1 || len(fields) > 2 {
+		return fmt.Errorf("invalid sample %q", line)
+	}
+	value, err := parseMetricValue(fields[0])
+	if err != nil {
+		return err
+	}
+	var ts time.Time
+	if len(fields) == 2 {
+		ms, err := strconv.ParseInt(fields[1], 10, 64)
+		if err != nil {
+			return fmt.Errorf("invalid timestamp %q", fields[1])
+		}
+		ts = time.UnixMilli(ms)
+	}
+
+	f, suffix := p.sampleFamily(name)
+	var special string
+	switch {
+	case f.Type == MetricHistogram && suffix == "_bucket":
+		special = "le"
+	case f.Type == MetricSummary && suffix == "":
+		special = "quantile"
+	}
+	bound := labels[special]
+	delete(labels, special)
+
+	metric := p.metric(f, labels)
+	metric.Timestamp = ts
+	switch {
+	case suffix == "_sum":
+		metric.Sum = value
+	case suffix == "_count":
+		metric.Count = value
+	case special == "le":
+		ub, err := parseMetricValue(bound)
+		if err != nil {
+			return fmt.Errorf("invalid bucket bound %q", bound)
+		}
+		metric.Buckets = append(metric.Buckets, Bucket{UpperBound: ub, Count: value})
+	case special == "quantile":
+		q, err := parseMetricValue(bound)
+		if err != nil {
+			return fmt.Errorf("invalid quantile %q", bound)
+		}
+		metric.Quantiles = append(metric.Quantiles, Quantile{Quantile: q, Value: value})
+	default:
+		metric.Value = value
+	}
+	return nil
+}
+
+// sampleFamily returns the family of a sample, along with the histogram or summary suffix of its name.
+func (p *metricsParser) sampleFamily(name string) (*MetricFamily, string) {
+	for _, suffix := range []string{"_bucket", "_sum", "_count"} {
+		base, ok := strings.CutSuffix(name, suffix)
+		if !ok {
+			continue
+		}
+		if f, ok := p.families[base]; ok && (f.Type == MetricHistogram || f.Type == MetricSummary) {
+			if suffix == "_bucket" && f.Type == MetricSummary {
+				break
+			}
+			return f, suffix
+		}
+	}
+	return p.family(name), ""
+}
+
+// metric returns the metric of the family with the given labels, adding it if needed.
+func (p *metricsParser) metric(f *MetricFamily, labels map[string]string) *Metric {
+	keys := make([]string, 0, len(labels))
+	for k := range labels {
+		keys = append(keys, k)
+	}
+	slices.Sort(keys)
+	var b strings.Builder
+	b.WriteString(f.Name)
+	for _, k := range keys {
+		fmt.Fprintf(&b, "\x00%s=%s", k, labels[k])
+	}
+	key := b.String()
+
+	if i, ok := p.index[key]; ok {
+		return &f.Metrics[i]
+	}
+	p.index[key] = len(f.Metrics)
+	f.Metrics = append(f.Metrics, Metric{Labels: labels})
+	return &f.Metrics[len(f.Metrics)-1]
+}
+
+// parseLabels parses the labels following the opening brace, and returns the rest of the line.
+func parseLabels(s string) (map[string]string, string, error) {
+	labels := make(map[string]string)
+	for {
+		s = strings.TrimLeft(s, " \t")
+		if rest, ok := strings.CutPrefix(s, "}"); ok {
+			return labels, rest, nil
+		}
+
+		eq := strings.IndexByte(s, '=')
+		if eq <= 0 {
+			return nil, "", fmt.Errorf("invalid labels near %q", s)
+		}
+		name := strings.TrimSpace(s[:eq])
+		s = strings.TrimLeft(s[eq+1:], " \t")
+		if !strings.HasPrefix(s, `"`) {
+			return nil, "", fmt.Errorf("unquoted value of label %s", name)
+		}
+
+		end := -1
+		for i := 1; i < len(s); i++ {
+			if s[i] == '\\' {
+				i++
+			} else if s[i] == '"' {
+				end = i
+				break
+			}
+		}
+		if end < 0 {
+			return nil, "", fmt.Errorf("unterminated value of label %s", name)
+		}
+		labels[name] = unescapeMetric(s[1:end], true)
+
+		s = strings.TrimLeft(s[end+1:], " \t")
+		s = strings.TrimPrefix(s, ",")
+	}
+}
+
+// parseMetricValue parses a sample value, including NaN and infinities.
+func parseMetricValue(s string) (float64, error) {
+	switch s {
+	case "+Inf", "Inf":
+		return math.Inf(1), nil
+	case "-Inf":
+		return math.Inf(-1), nil
+	case "NaN":
+		return math.NaN(), nil
+	}
+	v, err := strconv.ParseFloat(s, 64)
+	if err != nil {
+		return 0, fmt.Errorf("invalid value %q", s)
+	}
+	return v, nil
+}
+
+// unescapeMetric unescapes a help text, or a label value when quotes are escaped.
+func unescapeMetric(s string, quoted bool) string {
+	if !strings.Contains(s, `\`) {
+		return s
+	}
+	var b strings.Builder
+	for i := 0; i < len(s); i++ {
+		if s[i] != '\\' || i+1 == len(s) {
+			b.WriteByte(s[i])
+			continue
+		}
+		switch next := s[i+1]; {
+		case next == 'n':
+			b.WriteByte('\n')
+		case next == '\\', next == '"' && quoted:
+			b.WriteByte(next)
+		default:
+			b.WriteByte('\\')
+			b.WriteByte(next)
+		}
+		i++
+	}
+	return b.String()
+}
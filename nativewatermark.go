@@ -0,0 +1,113 @@
+package gotenberg
+
+import (
+	"errors"
+	"fmt"
+	"image/color"
+	"strconv"
+)
+
+// NativeWatermark configures LibreOffice's built-in watermark.
+// A tiled watermark repeats its text across the page and only accepts the text.
+type NativeWatermark struct {
+	Text string
+	// Color is the text color. Alternatively, ColorHex sets it as #RRGGBB.
+	Color    color.Color
+	ColorHex string
+	// FontName is the font of the text.
+	FontName string
+	// FontHeight is the font height in points.
+	FontHeight int
+	// RotateAngle is the rotation angle in degrees, from 0 to 359.
+	RotateAngle int
+	// Tiled repeats the text across the page instead of drawing it once.
+	Tiled bool
+}
+
+// Validate checks the consistency of the watermark.
+func (w NativeWatermark) Validate() error {
+	var errs []error
+
+	if w.Text == "" {
+		errs = append(errs, errors.New("empty text"))
+	}
+	if w.Color != nil && w.ColorHex != "" {
+		errs = append(errs, errors.New("color and hex color are mutually exclusive"))
+	}
+	if w.ColorHex != "" {
+		if _, err := parseHexColor(w.ColorHex); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	if w.FontHeight < 0 {
+		errs = append(errs, fmt.Errorf("invalid font height %d", w.FontHeight))
+	}
+	if w.RotateAngle < 0 || w.RotateAngle > 359 {
+		errs = append(errs, fmt.Errorf("rotate angle %d out of range [0, 359]", w.RotateAngle))
+	}
+	if w.Tiled && (w.Color != nil || w.ColorHex != "" || w.FontName != "" || w.FontHeight != 0 || w.RotateAngle != 0) {
+		errs = append(errs, errors.New("tiled watermark only accepts a text"))
+	}
+
+	return errors.Join(errs...)
+}
+
+// rgb returns the watermark color as a 0xRRGGBB integer, and whether a color is set.
+func (w NativeWatermark) rgb() (int, bool) {
+	switch {
+	case w.Color != nil:
+		c := color.RGBAModel.Convert(w.Color).(color.RGBA)
+		return int(c.R)<<16 | int(c.G)<<8 | int(c.B), true
+	case w.ColorHex != "":
+		rgb, _ := parseHexColor(w.ColorHex)
+		return rgb, true
+	default:
+		return 0, false
+	}
+}
+
+// parseHexColor parses a #RGB or #RRGGBB color as a 0xRRGGBB integer.
+func parseHexColor(s string) (int, error) {
+	if !hexColor.MatchString(s) {
+		return 0, fmt.Errorf("invalid color %q", s)
+	}
+	hex := s[1:]
+	if len(hex) == 3 {
+		hex = string([]byte{hex[0], hex[0], hex[1], hex[1], hex[2], hex[2]})
+	}
+	rgb, err := strconv.ParseInt(hex, 16, 32)
+	return int(rgb), err
+}
+
+// NativeWatermark validates the watermark and applies it using LibreOffice's built-in rendering.
+// It replaces the individual NativeWatermark* and NativeTiledWatermarkText setters, which must not be combined with it.
+// It requires Gotenberg 8.27.0.
+func (r *LibreOffice) NativeWatermark(w NativeWatermark) *LibreOffice {
+	if err := w.Validate(); err != nil {
+		r.fail(fmt.Errorf("native watermark: %w", err))
+		return r
+	}
+	if r.has("nativeWatermarkText") || r.has("nativeTiledWatermarkText") {
+		r.fail(errors.New("native watermark: a watermark is already set"))
+		return r
+	}
+
+	if w.Tiled {
+		return r.NativeTiledWatermarkText(w.Text)
+	}
+
+	r.NativeWatermarkText(w.Text)
+	if rgb, ok := w.rgb(); ok {
+		r.NativeWatermarkColor(strconv.Itoa(rgb))
+	}
+	if w.FontName != "" {
+		r.NativeWatermarkFontName(w.FontName)
+	}
+	if w.FontHeight != 0 {
+		r.NativeWatermarkFontHeight(w.FontHeight)
+	}
+	if w.RotateAngle != 0 {
+		r.NativeWatermarkRotateAngle(w.RotateAngle)
+	}
+	return r
+}
@@ -0,0 +1,205 @@
+package gotenberg
+
+import (
+	"fmt"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// PageRange represents an inclusive range of 1-based pages.
+// A To of 0 means the range runs up to the last page.
+type PageRange struct {
+	From int
+	To   int
+}
+
+// String formats the range as '5', '5-9' or '5-'.
+func (p PageRange) String() string {
+	switch {
+	case p.To == 0:
+		return strconv.Itoa(p.From) + "-"
+	case p.From == p.To:
+		return strconv.Itoa(p.From)
+	default:
+		return strconv.Itoa(p.From) + "-" + strconv.Itoa(p.To)
+	}
+}
+
+// PageRanges represents a page selection such as '1-5, 8, 11-13'.
+type PageRanges []PageRange
+
+// Pages returns a selection of the given single pages.
+func Pages(pages ...int) PageRanges {
+	return PageRanges(nil).Pages(pages...)
+}
+
+// Pages returns a copy of the selection extended with the given single pages.
+func (p PageRanges) Pages(pages ...int) PageRanges {
+	out := slices.Clone(p)
+	for _, page := range pages {
+		out = append(out, PageRange{From: page, To: page})
+	}
+	return out
+}
+
+// Range returns a copy of the selection extended with pages from to to, inclusive.
+// A to of 0 selects pages up to the last one.
+func (p PageRanges) Range(from, to int) PageRanges {
+	return append(slices.Clone(p), PageRange{From: from, To: to})
+}
+
+// ParsePageRanges parses a page selection such as '1-5, 8, 11-13' or '3-'.
+// The result is validated but not normalized.
+func ParsePageRanges(s string) (PageRanges, error) {
+	var ranges PageRanges
+	for _, part := range strings.Split(s, ",") {
+		part = strings.TrimSpace(part)
+		if part == "" {
+			return nil, fmt.Errorf("invalid page ranges %q: empty range", s)
+		}
+
+		from, to, isRange := strings.Cut(part, "-")
+		r := PageRange{}
+		var err error
+		if r.From, err = strconv.Atoi(strings.TrimSpace(from)); err != nil {
+			return nil, fmt.Errorf("invalid page ranges %q: invalid page %q", s, strings.TrimSpace(from))
+		}
+		switch to = strings.TrimSpace(to); {
+		case !isRange:
+			r.To = r.From
+		case to == "":
+			r.To = 0
+		default:
+			if r.To, err = strconv.Atoi(to); err != nil {
+				return nil, fmt.Errorf("invalid page ranges %q: invalid page %q", s, to)
+			}
+		}
+		ranges = append(ranges, r)
+	}
+
+	if err := ranges.Validate(); err != nil {
+		return nil, fmt.Errorf("invalid page ranges %q: %w", s, err)
+	}
+	return ranges, nil
+}
+
+// Validate checks that the selection is not empty, that pages start at 1
+// and that no range ends before it starts.
+func (p PageRanges) Validate() error {
+	if len(p) == 0 {
+		return fmt.Errorf("empty page selection")
+	}
+	for _, r := range p {
+		if r.From < 1 {
+			return fmt.Errorf("page %d out of range", r.From)
+		}
+		if r.To != 0 && r.To < r.From {
+			return fmt.Errorf("range %d-%d ends before it starts", r.From, r.To)
+		}
+	}
+	return nil
+}
+
+// Normalize returns a sorted copy of the selection where overlapping and adjacent ranges are merged.
+func (p PageRanges) Normalize() PageRanges {
+	if len(p) == 0 {
+		return nil
+	}
+
+	sorted := slices.Clone(p)
+	slices.SortFunc(sorted, func(a, b PageRange) int { return a.From - b.From })
+
+	out := PageRanges{sorted[0]}
+	for _, r := range sorted[1:] {
+		last := &out[len(out)-1]
+		switch {
+		case last.To == 0:
+			// The last range already runs up to the end of the document.
+		case r.From <= last.To+1:
+			if r.To == 0 || r.To > last.To {
+				last.To = r.To
+			}
+		default:
+			out = append(out, r)
+		}
+	}
+	return out
+}
+
+// Contains reports whether the page is part of the selection.
+func (p PageRanges) Contains(page int) bool {
+	for _, r := range p {
+		if page >= r.From && (r.To == 0 || page <= r.To) {
+			return true
+		}
+	}
+	return false
+}
+
+// String formats the selection as accepted by page selection setters, e.g. '1-5, 8, 11-13'.
+func (p PageRanges) String() string {
+	parts := make([]string, len(p))
+	for i, r := range p {
+		parts[i] = r.String()
+	}
+	return strings.Join(parts, ", ")
+}
+
+// pdfcpuTerm matches a term of the page selections of pdfcpu, which Gotenberg uses to split,
+// rotate and watermark PDFs: besides pages and ranges, even and odd pages, pages counted from the
+// last one (l, l-1), ranges open at the start (-3) and exclusions (!3, n3).
+var pdfcpuTerm = regexp.MustCompile(`^[!n]?(even|odd|-?([1-9][0-9]*|l(-[0-9]+)?)(-([1-9][0-9]*|l(-[0-9]+)?)?)?)$`)
+
+// parsePageSelection parses a pdfcpu page selection. Selections made of pages and ranges only are
+// returned as parsed by ParsePageRanges; other valid selections are returned as nil ranges.
+func parsePageSelection(s string) (PageRanges, error) {
+	ranges, err := ParsePageRanges(s)
+	if err == nil {
+		return ranges, nil
+	}
+	for _, part := range strings.Split(s, ",") {
+		if !pdfcpuTerm.MatchString(strings.TrimSpace(part)) {
+			return nil, err
+		}
+	}
+	return nil, nil
+}
+
+// pageRanges validates a page selection given to the named field and returns it formatted,
+// normalized if requested. Invalid selections are recorded as request errors.
+// An empty selection is passed through, leaving the default to Gotenberg.
+func (r *Request) pageRanges(field, value string, normalize bool) (string, bool) {
+	if strings.TrimSpace(value) == "" {
+		return value, true
+	}
+	ranges, err := ParsePageRanges(value)
+	if err != nil {
+		r.fail(fmt.Errorf("%s: %w", field, err))
+		return "", false
+	}
+	if normalize {
+		ranges = ranges.Normalize()
+	}
+	return ranges.String(), true
+}
+
+// pageSelection is pageRanges for the fields handled by pdfcpu, which also accepts its own page
+// selection syntax; such selections are passed through as is.
+func (r *Request) pageSelection(field, value string, normalize bool) (string, bool) {
+	if strings.TrimSpace(value) == "" {
+		return value, true
+	}
+	ranges, err := parsePageSelection(value)
+	switch {
+	case err != nil:
+		r.fail(fmt.Errorf("%s: %w", field, err))
+		return "", false
+	case ranges == nil:
+		return strings.TrimSpace(value), true
+	case normalize:
+		ranges = ranges.Normalize()
+	}
+	return ranges.String(), true
+}
@@ -0,0 +1,305 @@
+package gotenberg
+
+import (
+	"archive/zip"
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"mime"
+	"net/http"
+	"path"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// secret is a string, such as a password, that is redacted when printed or logged.
+type secret string
+
+// redacted replaces secrets when they are printed or logged.
+const redacted = "[REDACTED]"
+
+// String implements the fmt.Stringer interface.
+func (s secret) String() string {
+	if s == "" {
+		return ""
+	}
+	return redacted
+}
+
+// GoString implements the fmt.GoStringer interface.
+func (s secret) GoString() string {
+	return strconv.Quote(s.String())
+}
+
+// LogValue implements the slog.LogValuer interface.
+func (s secret) LogValue() slog.Value {
+	return slog.StringValue(s.String())
+}
+
+// sensitiveParams are the form parameters whose values are redacted in logs.
+var sensitiveParams = map[string]bool{"password": true, "userPassword": true, "ownerPassword": true}
+
+// LogValue implements the slog.LogValuer interface, describing the request with its passwords redacted.
+func (r *Request) LogValue() slog.Value {
+	params := make([]slog.Attr, 0, len(r.params))
+	for _, p := range r.params {
+		value := p.value
+		if sensitiveParams[p.key] {
+			value = secret(value).String()
+		}
+		params = append(params, slog.String(p.key, value))
+	}
+
+	files := make([]string, 0, len(r.files))
+	for _, f := range r.files {
+		name := f.field + ":" + f.Name
+		if f.password != "" {
+			name += " (password " + f.password.String() + ")"
+		}
+		files = append(files, name)
+	}
+
+	return slog.GroupValue(
+		slog.String("route", r.route),
+		slog.Attr{Key: "params", Value: slog.GroupValue(params...)},
+		slog.Any("files", files),
+	)
+}
+
+// Password sets the password for opening the source files.
+// Files added with ProtectedFile use their own password instead.
+func (r *LibreOffice) Password(password string) *LibreOffice {
+	r.password = secret(password)
+	return r
+}
+
+// ProtectedFile adds a file opened with its own password to the conversion request.
+// When files require different passwords, Send converts them with one request per password
+// and combines the results; see Send.
+func (r *LibreOffice) ProtectedFile(filename string, content io.Reader, password string) *LibreOffice {
+	r.File(filename, content)
+	r.files[len(r.files)-1].password = secret(password)
+	return r
+}
+
+// passwordGroup is a set of files sharing the same password.
+type passwordGroup struct {
+	password secret
+	files    []formFile
+	// download is set for the group converting the files of DownloadFrom.
+	download bool
+}
+
+// passwordGroups groups the given files of the conversion by password, in order of first
+// appearance. Downloaded files use the global password.
+func (r *LibreOffice) passwordGroups(files []formFile) []passwordGroup {
+	var groups []passwordGroup
+	group := func(password secret) *passwordGroup {
+		for i := range groups {
+			if groups[i].password == password {
+				return &groups[i]
+			}
+		}
+		groups = append(groups, passwordGroup{password: password})
+		return &groups[len(groups)-1]
+	}
+
+	for _, f := range files {
+		password := f.password
+		if password == "" {
+			password = r.password
+		}
+		g := group(password)
+		g.files = append(g.files, f)
+	}
+	if len(r.Df) > 0 {
+		group(r.password).download = true
+	}
+	return groups
+}
+
+// postProcessingParams are applied to the merged PDF rather than to each converted file.
+var postProcessingParams = map[string]bool{"merge": true, "pdfa": true, "pdfua": true, "flatten": true}
+
+// sendProtected converts files requiring different passwords with a request per password.
+// Merged conversions are merged in call order by a final PDF engines request; otherwise the
+// converted files are returned in call order in a single ZIP archive.
+func (r *LibreOffice) sendProtected() (*Response, error) {
+	if err := errors.Join(r.errs...); err != nil {
+		return nil, err
+	}
+	for key := range r.headers {
+		if strings.HasPrefix(key, "Gotenberg-Webhook") {
+			return nil, errors.New("per-file passwords: webhooks are not supported when files require different passwords")
+		}
+	}
+	merge := r.ordered
+	if merge && r.has("splitMode") {
+		return nil, errors.New("per-file passwords: merged conversions cannot be split when files require different passwords")
+	}
+
+	// Files are uploaded with their position among all files, so that their converted PDFs
+	// can be ordered by it. They are renamed on a copy so that Send can be called again.
+	files := slices.Clone(r.files)
+	width := len(strconv.Itoa(max(len(files)-1, 0)))
+	for i := range files {
+		files[i].Name = fmt.Sprintf("%0*d_%s", width, i, files[i].Name)
+	}
+	groups := r.passwordGroups(files)
+
+	var pdfs []NamedFile
+	var trace string
+	for _, g := range groups {
+		sub := r.clone(r.route)
+		if merge {
+			sub.Meta, sub.typedMeta = nil, nil
+		}
+		for _, p := range r.params {
+			if !merge || !postProcessingParams[p.key] {
+				sub.Param(p.key, p.value)
+			}
+		}
+		if g.password != "" {
+			sub.Param("password", string(g.password))
+		}
+		if g.download {
+			sub.Df = r.Df
+		}
+		sub.files = g.files
+
+		resp, err := sub.Send()
+		if err != nil {
+			return nil, err
+		}
+		converted, err := decodeConverted(resp, g.files)
+		if err != nil {
+			return nil, err
+		}
+		if trace == "" {
+			trace = resp.GotenbergTrace
+		}
+		pdfs = append(pdfs, converted...)
+	}
+
+	// Downloaded files, which carry no position, come last.
+	slices.SortStableFunc(pdfs, func(a, b NamedFile) int {
+		i, _ := uploadIndex(a.Name, len(files))
+		j, _ := uploadIndex(b.Name, len(files))
+		return i - j
+	})
+	for i := range pdfs {
+		_, pdfs[i].Name = uploadIndex(pdfs[i].Name, len(files))
+	}
+
+	if merge {
+		m := &PDFEngines{Request: r.clone("/forms/pdfengines/merge")}
+		m.ordered = true
+		for _, p := range r.params {
+			if postProcessingParams[p.key] && p.key != "merge" {
+				m.Param(p.key, p.value)
+			}
+		}
+		for _, f := range pdfs {
+			m.File(f.Name, f.Content)
+		}
+		return m.Send()
+	}
+	return zipResponse(pdfs, r.headers.Get("Gotenberg-Output-Filename"), trace)
+}
+
+// uploadPosition matches the position prefixed to the name of an uploaded file, and to the
+// name of the PDF converted from it.
+var uploadPosition = regexp.MustCompile(`^(\d+)_(.+)$`)
+
+// uploadIndex returns the upload position found in a filename, or n if there is none,
+// and the filename without it.
+func uploadIndex(name string, n int) (int, string) {
+	if m := uploadPosition.FindStringSubmatch(name); m != nil {
+		if i, err := strconv.Atoi(m[1]); err == nil && i < n {
+			return i, m[2]
+		}
+	}
+	return n, name
+}
+
+// decodeConverted reads and closes a conversion response, either a ZIP archive or a single PDF.
+// A single PDF converted from a single uploaded file is named after it as in archives, with
+// its extension replaced by .pdf.
+func decodeConverted(resp *Response, uploaded []formFile) ([]NamedFile, error) {
+	defer resp.Body.Close()
+
+	if err := resp.checkStatus(); err != nil {
+		return nil, err
+	}
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, err
+	}
+
+	if mediaType, _, _ := mime.ParseMediaType(resp.Header.Get("Content-Type")); mediaType != "application/zip" {
+		name := responseFilename(resp)
+		if len(uploaded) == 1 {
+			name = strings.TrimSuffix(uploaded[0].Name, path.Ext(uploaded[0].Name)) + ".pdf"
+		}
+		return []NamedFile{{Name: name, Content: bytes.NewReader(body)}}, nil
+	}
+
+	zr, err := zip.NewReader(bytes.NewReader(body), int64(len(body)))
+	if err != nil {
+		return nil, err
+	}
+	files := make([]NamedFile, 0, len(zr.File))
+	for _, f := range zr.File {
+		data, err := readZipFile(f)
+		if err != nil {
+			return nil, err
+		}
+		files = append(files, NamedFile{Name: f.Name, Content: bytes.NewReader(data)})
+	}
+	return files, nil
+}
+
+// zipResponse returns a response holding the files in a ZIP archive, as Gotenberg does
+// for conversions producing several files.
+func zipResponse(files []NamedFile, filename, trace string) (*Response, error) {
+	var buf bytes.Buffer
+	zw := zip.NewWriter(&buf)
+	for _, f := range files {
+		w, err := zw.Create(f.Name)
+		if err != nil {
+			return nil, err
+		}
+		if _, err := io.Copy(w, f.Content); err != nil {
+			return nil, err
+		}
+	}
+	if err := zw.Close(); err != nil {
+		return nil, err
+	}
+
+	if filename == "" {
+		filename = trace
+	}
+	header := make(http.Header)
+	header.Set("Content-Type", "application/zip")
+	header.Set("Content-Disposition", mime.FormatMediaType("attachment", map[string]string{"filename": filename + ".zip"}))
+	header.Set("Gotenberg-Trace", trace)
+
+	return &Response{
+		Response: &http.Response{
+			Status:        "200 OK",
+			StatusCode:    http.StatusOK,
+			Proto:         "HTTP/1.1",
+			ProtoMajor:    1,
+			ProtoMinor:    1,
+			Header:        header,
+			Body:          io.NopCloser(&buf),
+			ContentLength: int64(buf.Len()),
+		},
+		GotenbergTrace: trace,
+	}, nil
+}
@@ -9,67 +9,79 @@ import (
 
 // Convert creates a request to convert PDFs to PDF/A & PDF/UA.
 func (r *PDFEngines) Convert(ctx context.Context) *PDFEngines {
-	r.Req = r.HttpStream.Multipart(ctx, "/forms/pdfengines/convert")
+	r.open(ctx, "/forms/pdfengines/convert")
 	return r
 }
 
 // MetadataRead creates a request to read metadata from PDFs.
 func (r *PDFEngines) MetadataRead(ctx context.Context) *PDFEngines {
-	r.Req = r.HttpStream.Multipart(ctx, "/forms/pdfengines/metadata/read")
+	r.open(ctx, "/forms/pdfengines/metadata/read")
 	return r
 }
 
 // MetadataWrite creates a request to write metadata to PDFs.
 func (r *PDFEngines) MetadataWrite(ctx context.Context) *PDFEngines {
-	r.Req = r.HttpStream.Multipart(ctx, "/forms/pdfengines/metadata/write")
+	r.open(ctx, "/forms/pdfengines/metadata/write")
 	return r
 }
 
 // Merge creates a request to merge PDFs.
 func (r *PDFEngines) Merge(ctx context.Context) *PDFEngines {
-	r.Req = r.HttpStream.Multipart(ctx, "/forms/pdfengines/merge")
+	r.open(ctx, "/forms/pdfengines/merge")
 	return r
 }
 
 // Split creates a request to split PDFs.
 func (r *PDFEngines) Split(ctx context.Context) *PDFEngines {
-	r.Req = r.HttpStream.Multipart(ctx, "/forms/pdfengines/split")
+	r.open(ctx, "/forms/pdfengines/split")
 	return r
 }
 
 // Flatten creates a request to flatten PDFs.
 func (r *PDFEngines) Flatten(ctx context.Context) *PDFEngines {
-	r.Req = r.HttpStream.Multipart(ctx, "/forms/pdfengines/flatten")
+	r.open(ctx, "/forms/pdfengines/flatten")
 	return r
 }
 
 // Watermark creates a request to apply a watermark behind page content.
+// It requires Gotenberg 8.26.0.
 func (r *PDFEngines) Watermark(ctx context.Context) *PDFEngines {
-	r.Req = r.HttpStream.Multipart(ctx, "/forms/pdfengines/watermark")
+	r.openSince(ctx, "/forms/pdfengines/watermark", Version{8, 26, 0})
 	return r
 }
 
 // Stamp creates a request to apply a stamp on top of page content.
+// It requires Gotenberg 8.26.0.
 func (r *PDFEngines) Stamp(ctx context.Context) *PDFEngines {
-	r.Req = r.HttpStream.Multipart(ctx, "/forms/pdfengines/stamp")
+	r.openSince(ctx, "/forms/pdfengines/stamp", Version{8, 26, 0})
 	return r
 }
 
 // Rotate creates a request to rotate PDF pages by 90°, 180°, or 270°.
+// It requires Gotenberg 8.25.0.
 func (r *PDFEngines) Rotate(ctx context.Context) *PDFEngines {
-	r.Req = r.HttpStream.Multipart(ctx, "/forms/pdfengines/rotate")
+	r.openSince(ctx, "/forms/pdfengines/rotate", Version{8, 25, 0})
+	return r
+}
+
+// Encrypt creates a request to protect PDFs with passwords.
+// It requires Gotenberg 8.19.0.
+func (r *PDFEngines) Encrypt(ctx context.Context) *PDFEngines {
+	r.openSince(ctx, "/forms/pdfengines/encrypt", Version{8, 19, 0})
 	return r
 }
 
 // BookmarksRead creates a request to read the bookmark outline from PDF files as JSON.
+// It requires Gotenberg 8.24.0.
 func (r *PDFEngines) BookmarksRead(ctx context.Context) *PDFEngines {
-	r.Req = r.HttpStream.Multipart(ctx, "/forms/pdfengines/bookmarks/read")
+	r.openSince(ctx, "/forms/pdfengines/bookmarks/read", Version{8, 24, 0})
 	return r
 }
 
 // BookmarksWrite creates a request to write bookmarks to PDF files.
+// It requires Gotenberg 8.24.0.
 func (r *PDFEngines) BookmarksWrite(ctx context.Context) *PDFEngines {
-	r.Req = r.HttpStream.Multipart(ctx, "/forms/pdfengines/bookmarks/write")
+	r.openSince(ctx, "/forms/pdfengines/bookmarks/write", Version{8, 24, 0})
 	return r
 }
 
@@ -108,6 +120,12 @@ func (r *PDFEngines) File(filename string, content io.Reader) *PDFEngines {
 	return r
 }
 
+// FileSource adds a file provided by a FileSource to the request.
+func (r *PDFEngines) FileSource(src FileSource) *PDFEngines {
+	r.Request.FileSource(src)
+	return r
+}
+
 // WebhookURL sets the webhook URL and HTTP method for successful operations.
 func (r *PDFEngines) WebhookURL(url, method string) *PDFEngines {
 	r.Request.WebhookURL(url, method)
@@ -150,6 +168,18 @@ func (r *PDFEngines) Trace(trace string) *PDFEngines {
 	return r
 }
 
+// NoCache bypasses the cache of the client for the request.
+func (r *PDFEngines) NoCache() *PDFEngines {
+	r.Request.NoCache()
+	return r
+}
+
+// Progress sets a function reporting the upload of the files and the download of the response.
+func (r *PDFEngines) Progress(fn func(Progress)) *PDFEngines {
+	r.Request.Progress(fn)
+	return r
+}
+
 // Timeout sets a timeout for the request.
 func (r *PDFEngines) Timeout(duration time.Duration) *PDFEngines {
 	r.Request.Timeout(duration)
@@ -162,6 +192,12 @@ func (r *PDFEngines) Metadata(key, value string) *PDFEngines {
 	return r
 }
 
+// PDFMetadata sets typed metadata for the PDF, preserving value types.
+func (r *PDFEngines) PDFMetadata(m PDFMetadata) *PDFEngines {
+	r.Request.PDFMetadata(m)
+	return r
+}
+
 // PDFA converts to PDF/A format.
 func (r *PDFEngines) PDFA(pdfa string) *PDFEngines {
 	return r.Param("pdfa", pdfa)
@@ -177,9 +213,13 @@ func (r *PDFEngines) SplitMode(mode string) *PDFEngines {
 	return r.Param("splitMode", mode)
 }
 
-// SplitSpan sets the split span.
+// SplitSpan sets the split span: a page count in intervals mode, or page ranges in pages mode.
+// The span is validated; see PageRanges. The pdfcpu syntax, e.g. 'even' or 'l-2-', is accepted too.
 func (r *PDFEngines) SplitSpan(span string) *PDFEngines {
-	return r.Param("splitSpan", span)
+	if span, ok := r.pageSelection("splitSpan", span, false); ok {
+		r.Param("splitSpan", span)
+	}
+	return r
 }
 
 // SplitUnify specifies whether to unify split pages.
@@ -194,17 +234,24 @@ func (r *PDFEngines) FlattenPDF(flatten bool) *PDFEngines {
 
 // RotateAngle sets the rotation angle for pages.
 func (r *PDFEngines) RotateAngle(angle int) *PDFEngines {
-	return r.Param("rotateAngle", strconv.Itoa(angle))
+	r.paramSince("rotateAngle", strconv.Itoa(angle), Version{8, 25, 0})
+	return r
 }
 
-// RotatePages sets the page selection for rotation.
+// RotatePages sets the page selection for rotation, e.g., '1-5, 8, 11-13'.
+// The selection is validated and normalized; see PageRanges. The pdfcpu syntax, e.g. 'odd' or 'l',
+// is accepted too and passed as is.
 func (r *PDFEngines) RotatePages(pages string) *PDFEngines {
-	return r.Param("rotatePages", pages)
+	if pages, ok := r.pageSelection("rotatePages", pages, true); ok {
+		r.paramSince("rotatePages", pages, Version{8, 25, 0})
+	}
+	return r
 }
 
 // Bookmarks sets the bookmarks JSON.
 func (r *PDFEngines) Bookmarks(json string) *PDFEngines {
-	return r.Param("bookmarks", json)
+	r.paramSince("bookmarks", json, Version{8, 24, 0})
+	return r
 }
 
 // AutoIndexBookmarks extracts and reindexes existing bookmarks from input files during merge.
@@ -214,13 +261,23 @@ func (r *PDFEngines) AutoIndexBookmarks(v bool) *PDFEngines {
 
 // WatermarkFile adds a watermark source file (image or PDF) to the request.
 func (r *PDFEngines) WatermarkFile(filename string, content io.Reader) *PDFEngines {
-	r.Request.file("watermark", filename, content)
+	r.fileSince("watermark", filename, content, Version{8, 26, 0})
 	return r
 }
 
 // StampFile adds a stamp source file (image or PDF) to the request.
 func (r *PDFEngines) StampFile(filename string, content io.Reader) *PDFEngines {
-	r.Request.file("stamp", filename, content)
+	r.fileSince("stamp", filename, content, Version{8, 26, 0})
+	return r
+}
+
+// Encryption sets the password required to open the PDFs and, if not empty,
+// the password granting full permissions on them. It requires Gotenberg 8.19.0.
+func (r *PDFEngines) Encryption(userPassword, ownerPassword string) *PDFEngines {
+	r.paramSince("userPassword", userPassword, Version{8, 19, 0})
+	if ownerPassword != "" {
+		r.paramSince("ownerPassword", ownerPassword, Version{8, 19, 0})
+	}
 	return r
 }
 
@@ -0,0 +1,108 @@
+package gotenberg
+
+import (
+	"bytes"
+	"compress/zlib"
+	"io"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+var (
+	// pdfObject matches an indirect object, capturing its body.
+	pdfObject = regexp.MustCompile(`(?s)\d+\s+\d+\s+obj\b(.*?)\bendobj`)
+	// pdfPagesNode matches the type of page tree nodes.
+	pdfPagesNode = regexp.MustCompile(`/Type\s*/Pages\b`)
+	// pdfParent matches the parent of a page tree node, which the root lacks.
+	pdfParent = regexp.MustCompile(`/Parent\s`)
+	// pdfCount matches the number of pages under a page tree node.
+	pdfCount = regexp.MustCompile(`/Count\s+(\d+)`)
+	// pdfObjectStream matches the type of object streams.
+	pdfObjectStream = regexp.MustCompile(`/Type\s*/ObjStm\b`)
+	// pdfInt matches an integer entry of a dictionary, named by the first group.
+	pdfInt = regexp.MustCompile(`/(N|First)\s+(\d+)`)
+	// pdfStream matches the start of the data of a stream.
+	pdfStream = regexp.MustCompile(`\bstream\r?\n`)
+)
+
+// pdfPageCount returns the number of pages of a PDF, read from the /Count of the root of its page
+// tree, and false if it cannot be found. Objects compressed in object streams are read too.
+func pdfPageCount(data []byte) (int, bool) {
+	count, found := 0, false
+	var visit func(dict []byte)
+	visit = func(dict []byte) {
+		if !pdfPagesNode.Match(dict) || pdfParent.Match(dict) {
+			return
+		}
+		if m := pdfCount.FindSubmatch(dict); m != nil {
+			n, err := strconv.Atoi(string(m[1]))
+			if err == nil && n > count {
+				count, found = n, true
+			}
+		}
+	}
+
+	for _, m := range pdfObject.FindAllSubmatch(data, -1) {
+		body := m[1]
+		dict, stream := body, []byte(nil)
+		if loc := pdfStream.FindIndex(body); loc != nil {
+			dict, stream = body[:loc[0]], body[loc[1]:]
+		}
+		if pdfObjectStream.Match(dict) {
+			for _, obj := range objectStreamObjects(dict, stream) {
+				visit(obj)
+			}
+			continue
+		}
+		visit(dict)
+	}
+	return count, found
+}
+
+// objectStreamObjects returns the objects compressed in an object stream, given its dictionary
+// and its data. Only FlateDecode streams without predictor are supported.
+func objectStreamObjects(dict, stream []byte) [][]byte {
+	var n, first int
+	for _, m := range pdfInt.FindAllSubmatch(dict, -1) {
+		v, _ := strconv.Atoi(string(m[2]))
+		if string(m[1]) == "N" {
+			n = v
+		} else {
+			first = v
+		}
+	}
+	if s := string(dict); !strings.Contains(s, "/FlateDecode") || strings.Contains(s, "/DecodeParms") {
+		return nil
+	}
+
+	zr, err := zlib.NewReader(bytes.NewReader(stream))
+	if err != nil {
+		return nil
+	}
+	// Trailing bytes up to endstream make the reader fail once the objects are read.
+	content, _ := io.ReadAll(zr)
+	if first > len(content) {
+		return nil
+	}
+
+	// The header lists the object numbers and offsets, relative to first, of the n objects.
+	header := strings.Fields(string(content[:first]))
+	offsets := make([]int, 0, n)
+	for i := 1; i < len(header) && len(offsets) < n; i += 2 {
+		off, err := strconv.Atoi(header[i])
+		if err != nil || first+off > len(content) {
+			return nil
+		}
+		offsets = append(offsets, first+off)
+	}
+	objects := make([][]byte, len(offsets))
+	for i, off := range offsets {
+		end := len(content)
+		if i+1 < len(offsets) {
+			end = max(offsets[i+1], off)
+		}
+		objects[i] = content[off:end]
+	}
+	return objects
+}
@@ -0,0 +1,219 @@
+package gotenberg
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"path/filepath"
+	"strings"
+	"sync"
+)
+
+// StageFunc sends the request of a stage, given the outputs of its input stages as files.
+// The files are streamed from the input stages while the request is uploaded.
+type StageFunc func(ctx context.Context, c *Client, inputs []NamedFile) (*Response, error)
+
+// Stage is a step of a pipeline.
+type Stage struct {
+	pipeline *Pipeline
+	name     string
+	send     StageFunc
+	inputs   []*Stage
+	consumer *Stage
+}
+
+// StageError is the failure of a stage of a pipeline.
+type StageError struct {
+	Stage string
+	Err   error
+}
+
+// Error implements the error interface.
+func (e *StageError) Error() string {
+	return fmt.Sprintf("stage %s: %v", e.Stage, e.Err)
+}
+
+// Unwrap returns the error of the stage.
+func (e *StageError) Unwrap() error {
+	return e.Err
+}
+
+// errUpstream closes the input of a stage whose input stage failed.
+var errUpstream = errors.New("input stage failed")
+
+// Pipeline chains requests, the response of a stage being streamed through an io.Pipe as a file of
+// the request of the next one, without intermediate buffers. Independent stages run in parallel.
+// The output of a stage can be the input of a single other stage. On a throttled client, a stage
+// waits for its inputs to start before taking a slot, so that chains cannot exhaust the slots.
+type Pipeline struct {
+	client *Client
+	errs   []error
+}
+
+// Pipeline returns an empty pipeline.
+func (c *Client) Pipeline() *Pipeline {
+	return &Pipeline{client: c}
+}
+
+// Stage adds a stage sending the request built by send, with the outputs of the given stages as
+// inputs. The output of the stage is named name when it is the input of another stage; the Merge,
+// Watermark and Encrypt stages append .pdf to the names of their inputs lacking it, as Gotenberg
+// only accepts PDF files there.
+func (p *Pipeline) Stage(name string, send StageFunc, inputs ...*Stage) *Stage {
+	s := &Stage{pipeline: p, name: name, send: send, inputs: inputs}
+	for _, in := range inputs {
+		switch {
+		case in.pipeline != p:
+			p.errs = append(p.errs, fmt.Errorf("pipeline: stage %s belongs to another pipeline", in.name))
+		case in.consumer != nil:
+			p.errs = append(p.errs, fmt.Errorf("pipeline: stage %s is already an input of stage %s", in.name, in.consumer.name))
+		default:
+			in.consumer = s
+		}
+	}
+	return s
+}
+
+// HTML adds a stage converting an HTML document to PDF with Chromium.
+// If configure is not nil, it sets the options of the request.
+func (p *Pipeline) HTML(name string, html io.Reader, configure func(*Chromium)) *Stage {
+	return p.Stage(name, func(ctx context.Context, c *Client, _ []NamedFile) (*Response, error) {
+		r := c.Chromium().ConvertHTML(ctx, html)
+		if configure != nil {
+			configure(r)
+		}
+		return r.Send()
+	})
+}
+
+// Merge adds a stage merging the outputs of the given stages, in order.
+func (p *Pipeline) Merge(name string, inputs ...*Stage) *Stage {
+	return p.Stage(name, func(ctx context.Context, c *Client, files []NamedFile) (*Response, error) {
+		return c.PDFEngines().OrderedMerge(ctx, pdfInputs(files)...).Send()
+	}, inputs...)
+}
+
+// Watermark adds a stage applying a watermark to the output of the given stage.
+func (p *Pipeline) Watermark(name string, o WatermarkOptions, input *Stage) *Stage {
+	return p.Stage(name, func(ctx context.Context, c *Client, files []NamedFile) (*Response, error) {
+		files = pdfInputs(files)
+		return c.PDFEngines().Watermark(ctx).WatermarkOptions(o).File(files[0].Name, files[0].Content).Send()
+	}, input)
+}
+
+// Encrypt adds a stage protecting the output of the given stage with passwords.
+func (p *Pipeline) Encrypt(name, userPassword, ownerPassword string, input *Stage) *Stage {
+	return p.Stage(name, func(ctx context.Context, c *Client, files []NamedFile) (*Response, error) {
+		files = pdfInputs(files)
+		return c.PDFEngines().Encrypt(ctx).Encryption(userPassword, ownerPassword).File(files[0].Name, files[0].Content).Send()
+	}, input)
+}
+
+// pdfInputs appends the .pdf extension to the names of the given files lacking it.
+func pdfInputs(files []NamedFile) []NamedFile {
+	for i, f := range files {
+		if !strings.EqualFold(filepath.Ext(f.Name), ".pdf") {
+			files[i].Name += ".pdf"
+		}
+	}
+	return files
+}
+
+// Run runs the final stage and the stages it depends on, and returns the response of the final
+// stage, whose body the caller must close. When a stage fails, the other ones are cancelled and
+// the failures are returned as *StageError, joined.
+func (p *Pipeline) Run(ctx context.Context, final *Stage) (*Response, error) {
+	if err := errors.Join(p.errs...); err != nil {
+		return nil, err
+	}
+	if final.pipeline != p {
+		return nil, fmt.Errorf("pipeline: stage %s belongs to another pipeline", final.name)
+	}
+	ctx, cancel := context.WithCancel(ctx)
+
+	var mu sync.Mutex
+	var errs []error
+	fail := func(s *Stage, err error) {
+		mu.Lock()
+		defer mu.Unlock()
+		// Once cancelled, stages fail because of the first failure: only report their own errors.
+		if len(errs) > 0 && (errors.Is(err, context.Canceled) || errors.Is(err, errUpstream) || errors.Is(err, io.ErrClosedPipe)) {
+			return
+		}
+		errs = append(errs, &StageError{Stage: s.name, Err: err})
+		cancel()
+	}
+
+	readers := make(map[*Stage]*io.PipeReader)
+	writers := make(map[*Stage]*io.PipeWriter)
+	var stages []*Stage
+	var collect func(s *Stage)
+	collect = func(s *Stage) {
+		for _, in := range s.inputs {
+			readers[in], writers[in] = io.Pipe()
+			stages = append(stages, in)
+			collect(in)
+		}
+	}
+	collect(final)
+
+	// run sends the request of a stage, then closes its inputs to unblock them if it failed early.
+	run := func(s *Stage) (*Response, error) {
+		files := make([]NamedFile, len(s.inputs))
+		for i, in := range s.inputs {
+			files[i] = NamedFile{Name: in.name, Content: readers[in]}
+		}
+		resp, err := s.send(ctx, p.client, files)
+		if err == nil {
+			if err = resp.checkStatus(); err != nil {
+				resp.Body.Close()
+			}
+		}
+		if err != nil {
+			fail(s, err)
+		}
+		for _, in := range s.inputs {
+			readers[in].Close()
+		}
+		return resp, err
+	}
+
+	var wg sync.WaitGroup
+	for _, s := range stages {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			w := writers[s]
+			resp, err := run(s)
+			if err != nil {
+				w.CloseWithError(errUpstream)
+				return
+			}
+			_, err = io.Copy(w, resp.Body)
+			resp.Body.Close()
+			if err != nil {
+				fail(s, err)
+				w.CloseWithError(errUpstream)
+				return
+			}
+			w.Close()
+		}()
+	}
+
+	resp, err := run(final)
+	wg.Wait()
+	mu.Lock()
+	defer mu.Unlock()
+	if err != nil || len(errs) > 0 {
+		if err == nil {
+			resp.Body.Close()
+		}
+		cancel()
+		return nil, errors.Join(errs...)
+	}
+
+	// The pipeline context bounds the reading of the body, so it ends when the body is closed.
+	resp.Body = &cancelOnClose{ReadCloser: resp.Body, cancel: cancel}
+	return resp, nil
+}
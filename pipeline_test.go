@@ -0,0 +1,117 @@
+package gotenberg
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"path"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// echoServer answers every route with its uploaded files, in order, followed by the last segment of
+// the route, and records the largest number of concurrent requests to each module. Like Gotenberg,
+// it rejects files other than PDFs on the PDF engines routes.
+type echoServer struct {
+	mu       sync.Mutex
+	inFlight map[string]int
+	peak     map[string]int
+}
+
+func (s *echoServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	module := routeModule(r.URL.Path)
+	s.mu.Lock()
+	s.inFlight[module]++
+	s.peak[module] = max(s.peak[module], s.inFlight[module])
+	s.mu.Unlock()
+	defer func() {
+		s.mu.Lock()
+		s.inFlight[module]--
+		s.mu.Unlock()
+	}()
+
+	if err := r.ParseMultipartForm(1 << 20); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	var out strings.Builder
+	for _, fh := range r.MultipartForm.File["files"] {
+		if module == "pdfengines" && !strings.HasSuffix(fh.Filename, ".pdf") {
+			http.Error(w, "not a PDF: "+fh.Filename, http.StatusBadRequest)
+			return
+		}
+		f, err := fh.Open()
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		io.Copy(&out, f)
+		f.Close()
+	}
+	fmt.Fprintf(w, "%s|%s", out.String(), path.Base(r.URL.Path))
+}
+
+func TestPipelineThrottled(t *testing.T) {
+	tests := []struct {
+		name  string
+		build func(p *Pipeline) *Stage
+		want  string
+	}{
+		{
+			name: "chain",
+			build: func(p *Pipeline) *Stage {
+				html := p.HTML("page", strings.NewReader("a"), nil)
+				marked := p.Watermark("marked", WatermarkOptions{Source: WatermarkSourceText, Expression: "draft"}, html)
+				return p.Encrypt("final", "user", "owner", marked)
+			},
+			want: "a|html|watermark|encrypt",
+		},
+		{
+			name: "merge",
+			build: func(p *Pipeline) *Stage {
+				first := p.HTML("first", strings.NewReader("a"), nil)
+				second := p.HTML("second", strings.NewReader("b"), nil)
+				merged := p.Merge("merged", first, second)
+				return p.Encrypt("final", "user", "owner", merged)
+			},
+			want: "a|htmlb|html|merge|encrypt",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := &echoServer{inFlight: make(map[string]int), peak: make(map[string]int)}
+			srv := httptest.NewServer(s)
+			defer srv.Close()
+
+			c, err := NewClient(srv.Client(), srv.URL)
+			if err != nil {
+				t.Fatal(err)
+			}
+			ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+			defer cancel()
+			c.Throttle(ctx, ThrottleOptions{MinConcurrency: 1, MaxConcurrency: 1})
+
+			p := c.Pipeline()
+			resp, err := p.Run(ctx, tt.build(p))
+			if err != nil {
+				t.Fatalf("Run() error = %v", err)
+			}
+			defer resp.Body.Close()
+			got, err := io.ReadAll(resp.Body)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if string(got) != tt.want {
+				t.Errorf("Run() body = %q, want %q", got, tt.want)
+			}
+			if peak := s.peak["pdfengines"]; peak > 1 {
+				t.Errorf("%d concurrent pdfengines requests, want at most 1", peak)
+			}
+		})
+	}
+}
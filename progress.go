@@ -0,0 +1,147 @@
+package gotenberg
+
+import (
+	"io"
+	"os"
+	"sync"
+	"time"
+)
+
+// Progress is the state of the transfer of a request. Sizes are -1 when unknown.
+type Progress struct {
+	// File is the name of the file being uploaded, empty before the first file and once the
+	// response is received.
+	File string
+	// FileUploaded is the number of bytes of File uploaded, out of FileSize.
+	FileUploaded, FileSize int64
+	// Uploaded is the number of bytes of all files uploaded, out of UploadSize.
+	Uploaded, UploadSize int64
+	// Downloaded is the number of bytes of the response body read, out of DownloadSize.
+	Downloaded, DownloadSize int64
+	// Elapsed is the time since the request was sent, once admitted by the throttler of the client.
+	// For cached and shared responses, it is the time since Send was called.
+	Elapsed time.Duration
+}
+
+// Progress sets a function called as the files of the request are uploaded and its response
+// body is read, on the goroutines doing so. Calls are not concurrent.
+// File sizes are known for *os.File readers and readers with a Len method, such as *bytes.Reader.
+// Cached responses, and responses shared with the identical request of another caller, report
+// their download only: their files are not uploaded.
+func (r *Request) Progress(fn func(Progress)) *Request {
+	r.progress = fn
+	return r
+}
+
+// progressTracker reports the progress of a request.
+type progressTracker struct {
+	fn    func(Progress)
+	start time.Time
+	mu    sync.Mutex
+	p     Progress
+}
+
+// newProgressTracker returns a tracker reporting to fn, starting now.
+func newProgressTracker(fn func(Progress)) *progressTracker {
+	return &progressTracker{fn: fn, start: time.Now(), p: Progress{FileSize: -1, DownloadSize: -1}}
+}
+
+// sent restarts the clock as the request is sent.
+func (t *progressTracker) sent() {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.start = time.Now()
+}
+
+// uploads wraps the files to upload so that their reading is reported, and returns them.
+func (t *progressTracker) uploads(files []formFile) []formFile {
+	tracked := make([]formFile, len(files))
+	for i, f := range files {
+		size := readerSize(f.Content)
+		if size < 0 || t.p.UploadSize < 0 {
+			t.p.UploadSize = -1
+		} else {
+			t.p.UploadSize += size
+		}
+		tracked[i] = f
+		tracked[i].Content = &progressReader{Reader: f.Content, tracker: t, name: f.Name, size: size}
+	}
+	return tracked
+}
+
+// download wraps the body of the response so that its reading is reported.
+func (t *progressTracker) download(resp *Response) *Response {
+	t.mu.Lock()
+	t.p.File, t.p.FileUploaded, t.p.FileSize = "", 0, -1
+	t.p.DownloadSize = resp.ContentLength
+	t.mu.Unlock()
+	resp.Body = &progressBody{ReadCloser: resp.Body, tracker: t}
+	return resp
+}
+
+// report updates the progress under the lock and calls the progress function.
+func (t *progressTracker) report(update func(p *Progress)) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	update(&t.p)
+	t.p.Elapsed = time.Since(t.start)
+	t.fn(t.p)
+}
+
+// progressReader reports the upload of a file.
+type progressReader struct {
+	io.Reader
+	tracker *progressTracker
+	name    string
+	size    int64
+	read    int64
+}
+
+// Read implements the io.Reader interface.
+func (r *progressReader) Read(p []byte) (int, error) {
+	n, err := r.Reader.Read(p)
+	if n > 0 {
+		r.read += int64(n)
+		r.tracker.report(func(p *Progress) {
+			p.File, p.FileUploaded, p.FileSize = r.name, r.read, r.size
+			p.Uploaded += int64(n)
+		})
+	}
+	return n, err
+}
+
+// progressBody reports the reading of a response body.
+type progressBody struct {
+	io.ReadCloser
+	tracker *progressTracker
+}
+
+// Read implements the io.Reader interface.
+func (b *progressBody) Read(p []byte) (int, error) {
+	n, err := b.ReadCloser.Read(p)
+	if n > 0 {
+		b.tracker.report(func(p *Progress) {
+			p.Downloaded += int64(n)
+		})
+	}
+	return n, err
+}
+
+// readerSize returns the number of bytes left in a reader, or -1 if unknown.
+func readerSize(r io.Reader) int64 {
+	switch r := r.(type) {
+	case interface{ Len() int }:
+		return int64(r.Len())
+	case *os.File:
+		info, err := r.Stat()
+		if err != nil || !info.Mode().IsRegular() {
+			return -1
+		}
+		offset, err := r.Seek(0, io.SeekCurrent)
+		if err != nil {
+			return -1
+		}
+		return info.Size() - offset
+	}
+	return -1
+}
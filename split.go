@@ -0,0 +1,249 @@
+package gotenberg
+
+import (
+	"archive/zip"
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"path"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// SplitMode represents the way PDFs are split.
+type SplitMode string
+
+// Split modes.
+const (
+	SplitModeIntervals SplitMode = "intervals"
+	SplitModePages     SplitMode = "pages"
+)
+
+// Split configures how PDFs are split.
+// Use SplitIntervals or SplitPages to create one.
+type Split struct {
+	Mode  SplitMode
+	Span  string
+	Unify bool
+}
+
+// SplitIntervals splits PDFs into parts of n pages each.
+func SplitIntervals(n int) Split {
+	return Split{Mode: SplitModeIntervals, Span: strconv.Itoa(n)}
+}
+
+// SplitPages extracts the given pages, either into a single PDF per source file (unify)
+// or into a PDF per page range.
+func SplitPages(ranges PageRanges, unify bool) Split {
+	return Split{Mode: SplitModePages, Span: ranges.String(), Unify: unify}
+}
+
+// Validate checks the consistency of the split configuration.
+func (s Split) Validate() error {
+	switch s.Mode {
+	case SplitModeIntervals:
+		if n, err := strconv.Atoi(s.Span); err != nil || n < 1 {
+			return fmt.Errorf("invalid interval %q", s.Span)
+		}
+		if s.Unify {
+			return errors.New("unify only applies to pages mode")
+		}
+	case SplitModePages:
+		if _, err := parsePageSelection(s.Span); err != nil {
+			return err
+		}
+	default:
+		return fmt.Errorf("unknown split mode %q", s.Mode)
+	}
+	return nil
+}
+
+// split validates the split configuration and applies it to the request.
+func (r *Request) split(s Split) *Request {
+	if err := s.Validate(); err != nil {
+		return r.fail(fmt.Errorf("split: %w", err))
+	}
+
+	r.Param("splitMode", string(s.Mode))
+	r.Param("splitSpan", s.Span)
+	if s.Mode == SplitModePages {
+		r.Bool("splitUnify", s.Unify)
+	}
+	return r
+}
+
+// SplitBy validates the split configuration and applies it to the route.
+func (r *PDFEngines) SplitBy(s Split) *PDFEngines {
+	r.Request.split(s)
+	return r
+}
+
+// SplitBy validates the split configuration and applies it to the conversion.
+func (r *LibreOffice) SplitBy(s Split) *LibreOffice {
+	r.Request.split(s)
+	return r
+}
+
+// SplitPart is a PDF resulting from a split, annotated with the pages of the source file it contains.
+type SplitPart struct {
+	// Name is the filename of the part in the response.
+	Name string
+	// Source is the filename of the source file the part was extracted from.
+	Source string
+	// Index is the position of the part among the parts of its source file.
+	Index int
+	// Pages are the pages of the source file contained in the part.
+	// The end of the last interval is only known if the part's pages could be counted.
+	Pages PageRanges
+	// Data is the content of the part.
+	Data []byte
+}
+
+// splitPartName matches part names such as 'report_2.pdf' or 'report_3-4.pdf'.
+var splitPartName = regexp.MustCompile(`^(.*)_(\d+)(?:-(\d+))?\.pdf$`)
+
+// DecodeSplit reads and closes a split response, either a ZIP archive or a single PDF,
+// and returns its parts ordered by source file then by position.
+// The sources are the filenames of the uploaded files, in upload order.
+func DecodeSplit(resp *Response, s Split, sources ...string) ([]SplitPart, error) {
+	defer resp.Body.Close()
+
+	if err := resp.checkStatus(); err != nil {
+		return nil, err
+	}
+	if err := s.Validate(); err != nil {
+		return nil, fmt.Errorf("split: %w", err)
+	}
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, err
+	}
+
+	if mediaType, _, _ := mime.ParseMediaType(resp.Header.Get("Content-Type")); mediaType != "application/zip" {
+		part := SplitPart{Name: responseFilename(resp), Data: body}
+		if len(sources) > 0 {
+			part.Source = sources[0]
+		}
+		part.Pages = s.partPages(0, nil, body)
+		return []SplitPart{part}, nil
+	}
+
+	zr, err := zip.NewReader(bytes.NewReader(body), int64(len(body)))
+	if err != nil {
+		return nil, err
+	}
+
+	type entry struct {
+		part   SplitPart
+		number int
+		pages  PageRanges
+	}
+	bySource := make(map[string][]entry)
+	var order []string
+	for _, f := range zr.File {
+		data, err := readZipFile(f)
+		if err != nil {
+			return nil, err
+		}
+
+		e := entry{part: SplitPart{Name: f.Name, Source: f.Name, Data: data}}
+		if m := splitPartName.FindStringSubmatch(path.Base(f.Name)); m != nil {
+			e.part.Source = matchSource(m[1], sources)
+			e.number, _ = strconv.Atoi(m[2])
+			if m[3] != "" {
+				to, _ := strconv.Atoi(m[3])
+				e.pages = PageRanges{{From: e.number, To: to}}
+			}
+		}
+		if _, ok := bySource[e.part.Source]; !ok {
+			order = append(order, e.part.Source)
+		}
+		bySource[e.part.Source] = append(bySource[e.part.Source], e)
+	}
+
+	slices.SortStableFunc(order, func(a, b string) int {
+		return sourceRank(a, sources) - sourceRank(b, sources)
+	})
+
+	var parts []SplitPart
+	for _, source := range order {
+		entries := bySource[source]
+		slices.SortStableFunc(entries, func(a, b entry) int { return a.number - b.number })
+		for i, e := range entries {
+			e.part.Index = i
+			e.part.Pages = s.partPages(i, e.pages, e.part.Data)
+			parts = append(parts, e.part)
+		}
+	}
+	return parts, nil
+}
+
+// partPages returns the pages of the source file contained in the i-th part.
+// Pages found in the part name take precedence over the ones derived from the configuration.
+func (s Split) partPages(i int, named PageRanges, data []byte) PageRanges {
+	if named != nil {
+		return named
+	}
+
+	switch s.Mode {
+	case SplitModeIntervals:
+		n, _ := strconv.Atoi(s.Span)
+		from := i*n + 1
+		to := from + n - 1
+		if count, ok := pdfPageCount(data); ok && count > 0 && count < n {
+			to = from + count - 1
+		}
+		return PageRanges{{From: from, To: to}}
+	case SplitModePages:
+		ranges, _ := ParsePageRanges(s.Span)
+		if s.Unify {
+			return ranges
+		}
+		if i < len(ranges) {
+			return ranges[i : i+1]
+		}
+	}
+	return nil
+}
+
+// matchSource returns the source filename whose base name is base, or base itself.
+func matchSource(base string, sources []string) string {
+	for _, source := range sources {
+		if strings.TrimSuffix(source, path.Ext(source)) == base || source == base {
+			return source
+		}
+	}
+	return base
+}
+
+// sourceRank returns the position of the source in the upload order; unknown sources come last.
+func sourceRank(source string, sources []string) int {
+	if i := slices.Index(sources, source); i >= 0 {
+		return i
+	}
+	return len(sources)
+}
+
+// responseFilename returns the filename from the Content-Disposition header of the response.
+func responseFilename(resp *Response) string {
+	if _, params, err := mime.ParseMediaType(resp.Header.Get("Content-Disposition")); err == nil {
+		return params["filename"]
+	}
+	return ""
+}
+
+// readZipFile reads the content of a ZIP archive entry.
+func readZipFile(f *zip.File) ([]byte, error) {
+	rc, err := f.Open()
+	if err != nil {
+		return nil, err
+	}
+	defer rc.Close()
+
+	return io.ReadAll(rc)
+}
@@ -0,0 +1,214 @@
+package spreadsheet
+
+import (
+	"archive/zip"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const odsMimetype = "application/vnd.oasis.opendocument.spreadsheet"
+
+// odsStyles collects the automatic styles of an ODS document.
+type odsStyles struct {
+	b       strings.Builder
+	cells   map[string]string
+	columns map[float64]string
+}
+
+// cell returns the name of the cell style applying a number format.
+func (s *odsStyles) cell(format string, date bool) string {
+	if format == "" {
+		return ""
+	}
+	key := fmt.Sprint(date, format)
+	if name, ok := s.cells[key]; ok {
+		return name
+	}
+
+	n := len(s.cells) + 1
+	dataStyle := fmt.Sprintf("N%d", n)
+	if date {
+		s.b.WriteString(odsDateStyle(dataStyle, format))
+	} else {
+		s.b.WriteString(odsNumberStyle(dataStyle, format))
+	}
+	name := fmt.Sprintf("ce%d", n)
+	fmt.Fprintf(&s.b, `<style:style style:name="%s" style:family="table-cell" style:data-style-name="%s"/>`, name, dataStyle)
+	s.cells[key] = name
+	return name
+}
+
+// column returns the name of the column style of the given width, in characters.
+func (s *odsStyles) column(width float64) string {
+	if name, ok := s.columns[width]; ok {
+		return name
+	}
+	name := fmt.Sprintf("co%d", len(s.columns)+1)
+	// A character of the default font is about 0.2 cm wide.
+	fmt.Fprintf(&s.b, `<style:style style:name="%s" style:family="table-column"><style:table-column-properties style:column-width="%scm"/></style:style>`,
+		name, strconv.FormatFloat(width*0.2, 'f', 2, 64))
+	s.columns[width] = name
+	return name
+}
+
+// WriteODS encodes the workbook as an OpenDocument spreadsheet.
+// It fails if the sheet names are invalid; see Validate.
+func (w *Workbook) WriteODS(out io.Writer) error {
+	if err := w.Validate(); err != nil {
+		return err
+	}
+	styles := &odsStyles{cells: make(map[string]string), columns: make(map[float64]string)}
+	styles.b.WriteString(`<style:style style:name="ceH" style:family="table-cell"><style:text-properties fo:font-weight="bold"/></style:style>`)
+
+	var body strings.Builder
+	for i, s := range w.Sheets {
+		s.ods(&body, i, styles)
+	}
+
+	content := `<?xml version="1.0" encoding="UTF-8"?>` +
+		`<office:document-content xmlns:office="urn:oasis:names:tc:opendocument:xmlns:office:1.0"` +
+		` xmlns:style="urn:oasis:names:tc:opendocument:xmlns:style:1.0"` +
+		` xmlns:text="urn:oasis:names:tc:opendocument:xmlns:text:1.0"` +
+		` xmlns:table="urn:oasis:names:tc:opendocument:xmlns:table:1.0"` +
+		` xmlns:fo="urn:oasis:names:tc:opendocument:xmlns:xsl-fo-compatible:1.0"` +
+		` xmlns:number="urn:oasis:names:tc:opendocument:xmlns:datastyle:1.0" office:version="1.2">` +
+		`<office:automatic-styles>` + styles.b.String() + `</office:automatic-styles>` +
+		`<office:body><office:spreadsheet>` + body.String() + `</office:spreadsheet></office:body></office:document-content>`
+
+	manifest := `<?xml version="1.0" encoding="UTF-8"?>` +
+		`<manifest:manifest xmlns:manifest="urn:oasis:names:tc:opendocument:xmlns:manifest:1.0" manifest:version="1.2">` +
+		`<manifest:file-entry manifest:full-path="/" manifest:media-type="` + odsMimetype + `"/>` +
+		`<manifest:file-entry manifest:full-path="content.xml" manifest:media-type="text/xml"/>` +
+		`</manifest:manifest>`
+
+	zw := zip.NewWriter(out)
+	// The mimetype entry must come first and be stored uncompressed.
+	mw, err := zw.CreateHeader(&zip.FileHeader{Name: "mimetype", Method: zip.Store})
+	if err != nil {
+		return err
+	}
+	if _, err := io.WriteString(mw, odsMimetype); err != nil {
+		return err
+	}
+	if err := writeZipFile(zw, "META-INF/manifest.xml", manifest); err != nil {
+		return err
+	}
+	if err := writeZipFile(zw, "content.xml", content); err != nil {
+		return err
+	}
+	return zw.Close()
+}
+
+// ods writes the table of the sheet.
+func (s Sheet) ods(b *strings.Builder, index int, styles *odsStyles) {
+	fmt.Fprintf(b, `<table:table table:name="%s">`, escape(s.sheetName(index)))
+	for _, c := range s.Columns {
+		if c.Width > 0 {
+			fmt.Fprintf(b, `<table:table-column table:style-name="%s"/>`, styles.column(c.Width))
+		} else {
+			b.WriteString(`<table:table-column/>`)
+		}
+	}
+
+	if s.hasHeaders() {
+		b.WriteString("<table:table-row>")
+		for _, c := range s.Columns {
+			fmt.Fprintf(b, `<table:table-cell table:style-name="ceH" office:value-type="string"><text:p>%s</text:p></table:table-cell>`, escape(c.Header))
+		}
+		b.WriteString("</table:table-row>")
+	}
+
+	for _, values := range s.Rows {
+		b.WriteString("<table:table-row>")
+		for i, value := range values {
+			format := s.column(i).Format
+			switch kind, v := cell(value); kind {
+			case cellEmpty:
+				b.WriteString("<table:table-cell/>")
+			case cellString:
+				fmt.Fprintf(b, `<table:table-cell office:value-type="string"><text:p>%s</text:p></table:table-cell>`, escape(v.(string)))
+			case cellNumber:
+				f := strconv.FormatFloat(v.(float64), 'f', -1, 64)
+				fmt.Fprintf(b, `<table:table-cell%s office:value-type="float" office:value="%s"><text:p>%s</text:p></table:table-cell>`,
+					styleAttr(styles.cell(format, false)), f, f)
+			case cellBool:
+				fmt.Fprintf(b, `<table:table-cell office:value-type="boolean" office:boolean-value="%t"><text:p>%t</text:p></table:table-cell>`, v, v)
+			case cellDate:
+				if format == "" {
+					format = DefaultDateFormat
+				}
+				d := v.(time.Time).Format("2006-01-02T15:04:05")
+				fmt.Fprintf(b, `<table:table-cell%s office:value-type="date" office:date-value="%s"><text:p>%s</text:p></table:table-cell>`,
+					styleAttr(styles.cell(format, true)), d, d)
+			}
+		}
+		b.WriteString("</table:table-row>")
+	}
+	b.WriteString("</table:table>")
+}
+
+// styleAttr returns the table:style-name attribute for a style, if any.
+func styleAttr(name string) string {
+	if name == "" {
+		return ""
+	}
+	return ` table:style-name="` + name + `"`
+}
+
+// odsNumberStyle converts a number format such as "#,##0.00" or "0%" to an ODF data style.
+func odsNumberStyle(name, format string) string {
+	decimals := 0
+	if _, frac, ok := strings.Cut(format, "."); ok {
+		decimals = strings.Count(frac, "0") + strings.Count(frac, "#")
+	}
+	number := fmt.Sprintf(`<number:number number:decimal-places="%d" number:min-integer-digits="1"%s/>`,
+		decimals, map[bool]string{true: ` number:grouping="true"`}[strings.Contains(format, ",")])
+
+	if strings.HasSuffix(format, "%") {
+		return `<number:percentage-style style:name="` + name + `">` + number + `<number:text>%</number:text></number:percentage-style>`
+	}
+	return `<number:number-style style:name="` + name + `">` + number + `</number:number-style>`
+}
+
+// odsDateStyle converts a date format such as "yyyy-mm-dd hh:mm" to an ODF data style.
+// A run of m following an hour is read as minutes.
+func odsDateStyle(name, format string) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, `<number:date-style style:name="%s">`, name)
+
+	afterHour := false
+	for i := 0; i < len(format); {
+		c := format[i]
+		n := 1
+		for i+n < len(format) && format[i+n] == c {
+			n++
+		}
+		style := map[bool]string{true: ` number:style="long"`}[n >= 2]
+		switch c {
+		case 'y':
+			fmt.Fprintf(&b, `<number:year%s/>`, map[bool]string{true: ` number:style="long"`}[n >= 4])
+		case 'm':
+			if afterHour {
+				fmt.Fprintf(&b, `<number:minutes%s/>`, style)
+			} else {
+				fmt.Fprintf(&b, `<number:month%s/>`, style)
+			}
+		case 'd':
+			fmt.Fprintf(&b, `<number:day%s/>`, style)
+		case 'h':
+			fmt.Fprintf(&b, `<number:hours%s/>`, style)
+			afterHour = true
+		case 's':
+			fmt.Fprintf(&b, `<number:seconds%s/>`, style)
+		default:
+			fmt.Fprintf(&b, `<number:text>%s</number:text>`, escape(format[i:i+n]))
+		}
+		i += n
+	}
+
+	b.WriteString(`</number:date-style>`)
+	return b.String()
+}
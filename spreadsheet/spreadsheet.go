@@ -0,0 +1,300 @@
+// Package spreadsheet builds minimal XLSX and ODS workbooks from Go data in memory,
+// to be exported to PDF by LibreOffice.
+package spreadsheet
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/nativebpm/gotenberg/v8"
+)
+
+// Format represents the file format of a workbook.
+type Format string
+
+// Workbook formats.
+const (
+	XLSX Format = "xlsx"
+	ODS  Format = "ods"
+)
+
+// DefaultDateFormat is the number format of date cells whose column has no format.
+const DefaultDateFormat = "yyyy-mm-dd"
+
+// Column describes a column of a sheet.
+type Column struct {
+	// Header is the title of the column, written in bold on the first row.
+	Header string
+	// Width is the column width, in characters.
+	Width float64
+	// Format is the number format of the column's numeric and date cells, e.g. "#,##0.00" or "yyyy-mm-dd".
+	Format string
+}
+
+// Sheet is a named table of cells. Cells may be strings, booleans, integers, floats,
+// time.Time values or nil; other values are written as text.
+type Sheet struct {
+	Name    string
+	Columns []Column
+	Rows    [][]any
+}
+
+// Workbook is a set of sheets.
+type Workbook struct {
+	Sheets []Sheet
+}
+
+// New returns a workbook made of the given sheets.
+func New(sheets ...Sheet) *Workbook {
+	return &Workbook{Sheets: sheets}
+}
+
+// FromStructs returns a sheet with a column per exported field of the structs in rows, which must be a slice.
+// Columns are configured with the sheet tag: `sheet:"Header,width=12,format=#,##0.00"`; `sheet:"-"` skips a field.
+// The format option comes last, as it extends to the end of the tag.
+func FromStructs(name string, rows any) (Sheet, error) {
+	v := reflect.ValueOf(rows)
+	if v.Kind() != reflect.Slice && v.Kind() != reflect.Array {
+		return Sheet{}, fmt.Errorf("spreadsheet: %s is not a slice", v.Kind())
+	}
+
+	t := v.Type().Elem()
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	if t.Kind() != reflect.Struct {
+		return Sheet{}, fmt.Errorf("spreadsheet: %s is not a struct", t)
+	}
+
+	sheet := Sheet{Name: name}
+	var fields []int
+	for i := range t.NumField() {
+		f := t.Field(i)
+		tag := f.Tag.Get("sheet")
+		if !f.IsExported() || tag == "-" {
+			continue
+		}
+
+		col, err := parseTag(f.Name, tag)
+		if err != nil {
+			return Sheet{}, fmt.Errorf("spreadsheet: field %s: %w", f.Name, err)
+		}
+		sheet.Columns = append(sheet.Columns, col)
+		fields = append(fields, i)
+	}
+
+	for i := range v.Len() {
+		e := v.Index(i)
+		for e.Kind() == reflect.Pointer {
+			e = e.Elem()
+		}
+		row := make([]any, len(fields))
+		if e.IsValid() {
+			for j, f := range fields {
+				row[j] = e.Field(f).Interface()
+			}
+		}
+		sheet.Rows = append(sheet.Rows, row)
+	}
+	return sheet, nil
+}
+
+// parseTag parses a sheet struct tag.
+func parseTag(field, tag string) (Column, error) {
+	col := Column{Header: field}
+	parts := strings.Split(tag, ",")
+	if parts[0] != "" {
+		col.Header = parts[0]
+	}
+	for i, opt := range parts[1:] {
+		key, value, _ := strings.Cut(opt, "=")
+		switch key {
+		case "width":
+			w, err := strconv.ParseFloat(value, 64)
+			if err != nil {
+				return col, fmt.Errorf("invalid width %q", value)
+			}
+			col.Width = w
+		case "format":
+			col.Format = strings.Join(append([]string{value}, parts[i+2:]...), ",")
+			return col, nil
+		default:
+			return col, fmt.Errorf("unknown option %q", key)
+		}
+	}
+	return col, nil
+}
+
+// hasHeaders reports whether any column of the sheet has a header.
+func (s Sheet) hasHeaders() bool {
+	for _, c := range s.Columns {
+		if c.Header != "" {
+			return true
+		}
+	}
+	return false
+}
+
+// column returns the i-th column description, or a zero one.
+func (s Sheet) column(i int) Column {
+	if i < len(s.Columns) {
+		return s.Columns[i]
+	}
+	return Column{}
+}
+
+// sheetName returns the name of the i-th sheet, defaulting to SheetN.
+func (s Sheet) sheetName(i int) string {
+	if s.Name != "" {
+		return s.Name
+	}
+	return fmt.Sprintf("Sheet%d", i+1)
+}
+
+// Validate checks the sheet names against the rules of spreadsheet applications: at most 31
+// characters, none of []:*?/\, no leading or trailing apostrophe, and unique regardless of case.
+func (w *Workbook) Validate() error {
+	var errs []error
+	seen := make(map[string]bool, len(w.Sheets))
+	for i, s := range w.Sheets {
+		name := s.sheetName(i)
+		switch {
+		case utf8.RuneCountInString(name) > 31:
+			errs = append(errs, fmt.Errorf("sheet name %q is longer than 31 characters", name))
+		case strings.ContainsAny(name, `[]:*?/\`):
+			errs = append(errs, fmt.Errorf("sheet name %q contains one of []:*?/\\", name))
+		case strings.HasPrefix(name, "'") || strings.HasSuffix(name, "'"):
+			errs = append(errs, fmt.Errorf("sheet name %q starts or ends with an apostrophe", name))
+		}
+		if key := strings.ToLower(name); seen[key] {
+			errs = append(errs, fmt.Errorf("duplicate sheet name %q", name))
+		} else {
+			seen[key] = true
+		}
+	}
+	if err := errors.Join(errs...); err != nil {
+		return fmt.Errorf("spreadsheet: %w", err)
+	}
+	return nil
+}
+
+// Bytes encodes the workbook in the given format.
+func (w *Workbook) Bytes(format Format) ([]byte, error) {
+	var buf bytes.Buffer
+	var err error
+	switch format {
+	case XLSX:
+		err = w.WriteXLSX(&buf)
+	case ODS:
+		err = w.WriteODS(&buf)
+	default:
+		err = fmt.Errorf("spreadsheet: unknown format %q", format)
+	}
+	return buf.Bytes(), err
+}
+
+// Source returns a gotenberg.FileSource yielding the workbook encoded in the given format.
+func (w *Workbook) Source(name string, format Format) gotenberg.FileSource {
+	return source{w: w, name: name, format: format}
+}
+
+// source is a workbook bound to its filename and format.
+type source struct {
+	w      *Workbook
+	name   string
+	format Format
+}
+
+// Open implements the gotenberg.FileSource interface.
+func (s source) Open() (gotenberg.NamedFile, error) {
+	b, err := s.w.Bytes(s.format)
+	if err != nil {
+		return gotenberg.NamedFile{}, err
+	}
+	return gotenberg.NamedFile{Name: s.name + "." + string(s.format), Content: bytes.NewReader(b)}, nil
+}
+
+// Options configures the export of a workbook to PDF.
+type Options struct {
+	// Format is the format the workbook is uploaded in. Defaults to XLSX.
+	Format Format
+	// Filename is the name of the uploaded workbook, without extension. Defaults to "workbook".
+	Filename string
+	// Landscape sets the paper orientation to landscape.
+	Landscape bool
+	// SinglePageSheets puts every sheet on exactly one page.
+	SinglePageSheets bool
+	// Configure sets further options on the LibreOffice request.
+	Configure func(*gotenberg.LibreOffice)
+}
+
+// Convert exports the workbook to PDF through LibreOffice.
+func Convert(ctx context.Context, client *gotenberg.Client, w *Workbook, opts Options) (*gotenberg.Response, error) {
+	if opts.Format == "" {
+		opts.Format = XLSX
+	}
+	if opts.Filename == "" {
+		opts.Filename = "workbook"
+	}
+
+	r := client.LibreOffice().
+		Convert(ctx).
+		FileSource(w.Source(opts.Filename, opts.Format)).
+		Landscape(opts.Landscape).
+		SinglePageSheets(opts.SinglePageSheets)
+	if opts.Configure != nil {
+		opts.Configure(r)
+	}
+	return r.Send()
+}
+
+// cellKind classifies the value of a cell.
+type cellKind int
+
+const (
+	cellEmpty cellKind = iota
+	cellString
+	cellNumber
+	cellBool
+	cellDate
+)
+
+// cell returns the kind of a value along with its normalized form:
+// a string, a float64, a bool or a time.Time.
+func cell(value any) (cellKind, any) {
+	v := reflect.ValueOf(value)
+	for v.Kind() == reflect.Pointer || v.Kind() == reflect.Interface {
+		if v.IsNil() {
+			return cellEmpty, nil
+		}
+		v = v.Elem()
+	}
+	if !v.IsValid() {
+		return cellEmpty, nil
+	}
+
+	if t, ok := v.Interface().(time.Time); ok {
+		return cellDate, t
+	}
+	switch v.Kind() {
+	case reflect.Bool:
+		return cellBool, v.Bool()
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return cellNumber, float64(v.Int())
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return cellNumber, float64(v.Uint())
+	case reflect.Float32, reflect.Float64:
+		return cellNumber, v.Float()
+	case reflect.String:
+		return cellString, v.String()
+	default:
+		return cellString, fmt.Sprint(v.Interface())
+	}
+}
@@ -0,0 +1,213 @@
+package spreadsheet
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/xml"
+	"io"
+	"strings"
+	"testing"
+	"time"
+)
+
+// unzip returns the entries of an archive by name, in order.
+func unzip(t *testing.T, b []byte) (map[string]string, []*zip.File) {
+	t.Helper()
+	zr, err := zip.NewReader(bytes.NewReader(b), int64(len(b)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	entries := make(map[string]string, len(zr.File))
+	for _, f := range zr.File {
+		rc, err := f.Open()
+		if err != nil {
+			t.Fatal(err)
+		}
+		content, err := io.ReadAll(rc)
+		rc.Close()
+		if err != nil {
+			t.Fatal(err)
+		}
+		entries[f.Name] = string(content)
+	}
+	return entries, zr.File
+}
+
+// between returns the part of s from the first start to the following end, both included.
+func between(s, start, end string) string {
+	i := strings.Index(s, start)
+	if i < 0 {
+		return ""
+	}
+	j := strings.Index(s[i:], end)
+	if j < 0 {
+		return ""
+	}
+	return s[i : i+j+len(end)]
+}
+
+var date = time.Date(2024, time.January, 2, 0, 0, 0, 0, time.UTC)
+
+func TestWriteXLSX(t *testing.T) {
+	tests := []struct {
+		name  string
+		sheet Sheet
+		// want is the sheet data of the worksheet.
+		want string
+	}{
+		{
+			name:  "headers and cells",
+			sheet: Sheet{Name: "R&D", Columns: []Column{{Header: "Name"}, {Header: "Total"}}, Rows: [][]any{{"Ink & paper", 12.5}}},
+			want: `<sheetData>` +
+				`<row r="1"><c r="A1" t="inlineStr" s="1"><is><t>Name</t></is></c><c r="B1" t="inlineStr" s="1"><is><t>Total</t></is></c></row>` +
+				`<row r="2"><c r="A2" t="inlineStr"><is><t xml:space="preserve">Ink &amp; paper</t></is></c><c r="B2" s="0"><v>12.5</v></c></row>` +
+				`</sheetData>`,
+		},
+		{
+			name:  "booleans and empty cells",
+			sheet: Sheet{Rows: [][]any{{true, nil, 3}}},
+			want:  `<sheetData><row r="1"><c r="A1" t="b"><v>1</v></c><c r="C1" s="0"><v>3</v></c></row></sheetData>`,
+		},
+		{
+			name:  "formats",
+			sheet: Sheet{Columns: []Column{{Format: "0.00"}, {}}, Rows: [][]any{{2, date}}},
+			want:  `<sheetData><row r="1"><c r="A1" s="2"><v>2</v></c><c r="B1" s="3"><v>45293</v></c></row></sheetData>`,
+		},
+		{
+			name:  "widths",
+			sheet: Sheet{Columns: []Column{{Width: 12.5}, {}, {Width: 8}}},
+			want:  `<cols><col min="1" max="1" width="12.5" customWidth="1"/><col min="3" max="3" width="8" customWidth="1"/></cols><sheetData></sheetData>`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			b, err := New(tt.sheet).Bytes(XLSX)
+			if err != nil {
+				t.Fatal(err)
+			}
+			entries, _ := unzip(t, b)
+
+			sheet, ok := entries["xl/worksheets/sheet1.xml"]
+			if !ok {
+				t.Fatalf("no worksheet in %v", entries)
+			}
+			if err := xml.Unmarshal([]byte(sheet), new(any)); err != nil {
+				t.Errorf("worksheet is not well-formed: %v", err)
+			}
+			got := strings.TrimSuffix(strings.TrimPrefix(sheet, xml.Header+`<worksheet xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main">`), "</worksheet>")
+			if got != tt.want {
+				t.Errorf("got\n%s\nwant\n%s", got, tt.want)
+			}
+			if name := escape(tt.sheet.sheetName(0)); !strings.Contains(entries["xl/workbook.xml"], `<sheet name="`+name+`"`) {
+				t.Errorf("workbook does not name the sheet %s:\n%s", name, entries["xl/workbook.xml"])
+			}
+			for _, part := range []string{"[Content_Types].xml", "_rels/.rels", "xl/_rels/workbook.xml.rels", "xl/styles.xml"} {
+				if _, ok := entries[part]; !ok {
+					t.Errorf("missing part %s", part)
+				}
+			}
+		})
+	}
+}
+
+func TestWriteODS(t *testing.T) {
+	tests := []struct {
+		name  string
+		sheet Sheet
+		// want is the table of the sheet.
+		want string
+	}{
+		{
+			name:  "headers and cells",
+			sheet: Sheet{Name: "R&D", Columns: []Column{{Header: "Name"}, {Header: "Total"}}, Rows: [][]any{{"Ink & paper", 12.5}}},
+			want: `<table:table table:name="R&amp;D"><table:table-column/><table:table-column/>` +
+				`<table:table-row><table:table-cell table:style-name="ceH" office:value-type="string"><text:p>Name</text:p></table:table-cell>` +
+				`<table:table-cell table:style-name="ceH" office:value-type="string"><text:p>Total</text:p></table:table-cell></table:table-row>` +
+				`<table:table-row><table:table-cell office:value-type="string"><text:p>Ink &amp; paper</text:p></table:table-cell>` +
+				`<table:table-cell office:value-type="float" office:value="12.5"><text:p>12.5</text:p></table:table-cell></table:table-row>` +
+				`</table:table>`,
+		},
+		{
+			name:  "booleans and empty cells",
+			sheet: Sheet{Rows: [][]any{{true, nil}}},
+			want: `<table:table table:name="Sheet1"><table:table-row>` +
+				`<table:table-cell office:value-type="boolean" office:boolean-value="true"><text:p>true</text:p></table:table-cell>` +
+				`<table:table-cell/></table:table-row></table:table>`,
+		},
+		{
+			name:  "formats",
+			sheet: Sheet{Columns: []Column{{Format: "0.00", Width: 10}, {}}, Rows: [][]any{{2, date}}},
+			want: `<table:table table:name="Sheet1"><table:table-column table:style-name="co1"/><table:table-column/><table:table-row>` +
+				`<table:table-cell table:style-name="ce1" office:value-type="float" office:value="2"><text:p>2</text:p></table:table-cell>` +
+				`<table:table-cell table:style-name="ce2" office:value-type="date" office:date-value="2024-01-02T00:00:00"><text:p>2024-01-02T00:00:00</text:p></table:table-cell>` +
+				`</table:table-row></table:table>`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			b, err := New(tt.sheet).Bytes(ODS)
+			if err != nil {
+				t.Fatal(err)
+			}
+			entries, files := unzip(t, b)
+
+			if files[0].Name != "mimetype" || files[0].Method != zip.Store || entries["mimetype"] != odsMimetype {
+				t.Errorf("first entry = %s, method %d, want the stored mimetype", files[0].Name, files[0].Method)
+			}
+			content := entries["content.xml"]
+			if err := xml.Unmarshal([]byte(content), new(any)); err != nil {
+				t.Errorf("content is not well-formed: %v", err)
+			}
+			if got := between(content, "<table:table ", "</table:table>"); got != tt.want {
+				t.Errorf("got\n%s\nwant\n%s", got, tt.want)
+			}
+			if _, ok := entries["META-INF/manifest.xml"]; !ok {
+				t.Error("missing manifest")
+			}
+		})
+	}
+}
+
+func TestValidate(t *testing.T) {
+	tests := []struct {
+		name  string
+		names []string
+		err   string
+	}{
+		{name: "valid", names: []string{"Sales 2024", "Été", ""}},
+		{name: "default names", names: []string{"", ""}},
+		{name: "too long", names: []string{strings.Repeat("x", 32)}, err: "longer than 31 characters"},
+		{name: "31 characters", names: []string{strings.Repeat("é", 31)}},
+		{name: "forbidden character", names: []string{"Q1/Q2"}, err: "contains one of"},
+		{name: "leading apostrophe", names: []string{"'Totals"}, err: "apostrophe"},
+		{name: "trailing apostrophe", names: []string{"Totals'"}, err: "apostrophe"},
+		{name: "duplicate regardless of case", names: []string{"Data", "DATA"}, err: `duplicate sheet name "DATA"`},
+		{name: "duplicate default name", names: []string{"", "Sheet1"}, err: `duplicate sheet name "Sheet1"`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := New()
+			for _, name := range tt.names {
+				w.Sheets = append(w.Sheets, Sheet{Name: name})
+			}
+			err := w.Validate()
+			if tt.err == "" {
+				if err != nil {
+					t.Fatal(err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.err) {
+				t.Fatalf("error = %v, want %q", err, tt.err)
+			}
+			for _, format := range []Format{XLSX, ODS} {
+				if _, err := w.Bytes(format); err == nil {
+					t.Errorf("%s: no error for invalid sheet names", format)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,197 @@
+package spreadsheet
+
+import (
+	"archive/zip"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// xlsxEpoch is the origin of Excel date serial numbers.
+var xlsxEpoch = time.Date(1899, time.December, 30, 0, 0, 0, 0, time.UTC)
+
+// xlsxStyles assigns cell style indices to number formats.
+// Style 0 is the default style and style 1 the bold header style.
+type xlsxStyles struct {
+	formats []string
+	index   map[string]int
+}
+
+// style returns the cell style index of a number format.
+func (s *xlsxStyles) style(format string) int {
+	if format == "" {
+		return 0
+	}
+	if i, ok := s.index[format]; ok {
+		return i
+	}
+	s.formats = append(s.formats, format)
+	s.index[format] = len(s.formats) + 1
+	return s.index[format]
+}
+
+// WriteXLSX encodes the workbook as an Office Open XML spreadsheet.
+// It fails if the sheet names are invalid; see Validate.
+func (w *Workbook) WriteXLSX(out io.Writer) error {
+	if err := w.Validate(); err != nil {
+		return err
+	}
+	styles := &xlsxStyles{index: make(map[string]int)}
+	sheets := make([]string, len(w.Sheets))
+	for i, s := range w.Sheets {
+		sheets[i] = s.xlsx(styles)
+	}
+
+	var workbook, rels, types strings.Builder
+	for i, s := range w.Sheets {
+		fmt.Fprintf(&workbook, `<sheet name="%s" sheetId="%d" r:id="rId%d"/>`, escape(s.sheetName(i)), i+1, i+1)
+		fmt.Fprintf(&rels, `<Relationship Id="rId%d" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/worksheet" Target="worksheets/sheet%d.xml"/>`, i+1, i+1)
+		fmt.Fprintf(&types, `<Override PartName="/xl/worksheets/sheet%d.xml" ContentType="application/vnd.openxmlformats-officedocument.spreadsheetml.worksheet+xml"/>`, i+1)
+	}
+	fmt.Fprintf(&rels, `<Relationship Id="rId%d" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/styles" Target="styles.xml"/>`, len(w.Sheets)+1)
+
+	parts := []struct {
+		name    string
+		content string
+	}{
+		{"[Content_Types].xml", xml.Header +
+			`<Types xmlns="http://schemas.openxmlformats.org/package/2006/content-types">` +
+			`<Default Extension="rels" ContentType="application/vnd.openxmlformats-package.relationships+xml"/>` +
+			`<Default Extension="xml" ContentType="application/xml"/>` +
+			`<Override PartName="/xl/workbook.xml" ContentType="application/vnd.openxmlformats-officedocument.spreadsheetml.sheet.main+xml"/>` +
+			`<Override PartName="/xl/styles.xml" ContentType="application/vnd.openxmlformats-officedocument.spreadsheetml.styles+xml"/>` +
+			types.String() + `</Types>`},
+		{"_rels/.rels", xml.Header +
+			`<Relationships xmlns="http://schemas.openxmlformats.org/package/2006/relationships">` +
+			`<Relationship Id="rId1" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/officeDocument" Target="xl/workbook.xml"/>` +
+			`</Relationships>`},
+		{"xl/workbook.xml", xml.Header +
+			`<workbook xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main" xmlns:r="http://schemas.openxmlformats.org/officeDocument/2006/relationships">` +
+			`<sheets>` + workbook.String() + `</sheets></workbook>`},
+		{"xl/_rels/workbook.xml.rels", xml.Header +
+			`<Relationships xmlns="http://schemas.openxmlformats.org/package/2006/relationships">` +
+			rels.String() + `</Relationships>`},
+		{"xl/styles.xml", styles.xml()},
+	}
+
+	zw := zip.NewWriter(out)
+	for _, p := range parts {
+		if err := writeZipFile(zw, p.name, p.content); err != nil {
+			return err
+		}
+	}
+	for i, s := range sheets {
+		if err := writeZipFile(zw, fmt.Sprintf("xl/worksheets/sheet%d.xml", i+1), s); err != nil {
+			return err
+		}
+	}
+	return zw.Close()
+}
+
+// xml returns the styles part, with custom number formats numbered from 164.
+func (s *xlsxStyles) xml() string {
+	var numFmts, xfs strings.Builder
+	for i, f := range s.formats {
+		fmt.Fprintf(&numFmts, `<numFmt numFmtId="%d" formatCode="%s"/>`, 164+i, escape(f))
+		fmt.Fprintf(&xfs, `<xf numFmtId="%d" fontId="0" fillId="0" borderId="0" xfId="0" applyNumberFormat="1"/>`, 164+i)
+	}
+
+	return xml.Header +
+		`<styleSheet xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main">` +
+		fmt.Sprintf(`<numFmts count="%d">%s</numFmts>`, len(s.formats), numFmts.String()) +
+		`<fonts count="2"><font><sz val="11"/><name val="Calibri"/></font><font><b/><sz val="11"/><name val="Calibri"/></font></fonts>` +
+		`<fills count="2"><fill><patternFill patternType="none"/></fill><fill><patternFill patternType="gray125"/></fill></fills>` +
+		`<borders count="1"><border><left/><right/><top/><bottom/><diagonal/></border></borders>` +
+		`<cellStyleXfs count="1"><xf numFmtId="0" fontId="0" fillId="0" borderId="0"/></cellStyleXfs>` +
+		fmt.Sprintf(`<cellXfs count="%d">`, len(s.formats)+2) +
+		`<xf numFmtId="0" fontId="0" fillId="0" borderId="0" xfId="0"/>` +
+		`<xf numFmtId="0" fontId="1" fillId="0" borderId="0" xfId="0" applyFont="1"/>` +
+		xfs.String() + `</cellXfs></styleSheet>`
+}
+
+// xlsx returns the worksheet part of the sheet.
+func (s Sheet) xlsx(styles *xlsxStyles) string {
+	var b strings.Builder
+	b.WriteString(xml.Header)
+	b.WriteString(`<worksheet xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main">`)
+
+	var cols strings.Builder
+	for i, c := range s.Columns {
+		if c.Width > 0 {
+			fmt.Fprintf(&cols, `<col min="%d" max="%d" width="%s" customWidth="1"/>`, i+1, i+1, strconv.FormatFloat(c.Width, 'f', -1, 64))
+		}
+	}
+	if cols.Len() > 0 {
+		b.WriteString("<cols>" + cols.String() + "</cols>")
+	}
+
+	b.WriteString("<sheetData>")
+	row := 1
+	if s.hasHeaders() {
+		fmt.Fprintf(&b, `<row r="%d">`, row)
+		for i, c := range s.Columns {
+			fmt.Fprintf(&b, `<c r="%s" t="inlineStr" s="1"><is><t>%s</t></is></c>`, cellRef(i, row), escape(c.Header))
+		}
+		b.WriteString("</row>")
+		row++
+	}
+	for _, values := range s.Rows {
+		fmt.Fprintf(&b, `<row r="%d">`, row)
+		for i, value := range values {
+			format := s.column(i).Format
+			ref := cellRef(i, row)
+			switch kind, v := cell(value); kind {
+			case cellString:
+				fmt.Fprintf(&b, `<c r="%s" t="inlineStr"><is><t xml:space="preserve">%s</t></is></c>`, ref, escape(v.(string)))
+			case cellNumber:
+				fmt.Fprintf(&b, `<c r="%s" s="%d"><v>%s</v></c>`, ref, styles.style(format), strconv.FormatFloat(v.(float64), 'f', -1, 64))
+			case cellBool:
+				fmt.Fprintf(&b, `<c r="%s" t="b"><v>%d</v></c>`, ref, map[bool]int{false: 0, true: 1}[v.(bool)])
+			case cellDate:
+				if format == "" {
+					format = DefaultDateFormat
+				}
+				fmt.Fprintf(&b, `<c r="%s" s="%d"><v>%s</v></c>`, ref, styles.style(format), strconv.FormatFloat(xlsxSerial(v.(time.Time)), 'f', -1, 64))
+			}
+		}
+		b.WriteString("</row>")
+		row++
+	}
+	b.WriteString("</sheetData></worksheet>")
+	return b.String()
+}
+
+// xlsxSerial returns the Excel serial number of a date, ignoring its time zone.
+func xlsxSerial(t time.Time) float64 {
+	wall := time.Date(t.Year(), t.Month(), t.Day(), t.Hour(), t.Minute(), t.Second(), t.Nanosecond(), time.UTC)
+	return wall.Sub(xlsxEpoch).Hours() / 24
+}
+
+// cellRef returns the A1 reference of the cell at the 0-based column and 1-based row.
+func cellRef(col, row int) string {
+	name := ""
+	for col++; col > 0; col = (col - 1) / 26 {
+		name = string(rune('A'+(col-1)%26)) + name
+	}
+	return name + strconv.Itoa(row)
+}
+
+// escape escapes text for XML character data and attribute values.
+func escape(s string) string {
+	var b strings.Builder
+	_ = xml.EscapeText(&b, []byte(s))
+	return b.String()
+}
+
+// writeZipFile adds a deflated entry to the archive.
+func writeZipFile(zw *zip.Writer, name, content string) error {
+	w, err := zw.Create(name)
+	if err != nil {
+		return err
+	}
+	_, err = io.WriteString(w, content)
+	return err
+}
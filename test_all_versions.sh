@@ -36,17 +36,8 @@ for VERSION in "${VERSIONS[@]}"; do
         continue
     fi
 
-    # Wait for the service to be healthy (up to 15 seconds)
-    HEALTHY=false
-    for i in {1..15}; do
-        if curl -s http://localhost:3000/health | grep -q '"status":"up"'; then
-            HEALTHY=true
-            break
-        fi
-        sleep 1
-    done
-
-    if [ "$HEALTHY" = false ]; then
+    # Wait for the service and its modules to be healthy (up to 15 seconds)
+    if ! go run ./cmd/gotenberg -url http://localhost:3000 health -wait 15s chromium libreoffice > /dev/null 2>&1; then
         echo "Gotenberg service is not healthy or did not start in time."
         docker logs gotenberg
         docker rm -f gotenberg &>/dev/null
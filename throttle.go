@@ -0,0 +1,260 @@
+package gotenberg
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+)
+
+// ThrottleOptions configures the adaptive throttling of a client.
+type ThrottleOptions struct {
+	// MinConcurrency and MaxConcurrency bound the number of concurrent requests to each module;
+	// they default to 1 and 8. Limits start at MaxConcurrency.
+	MinConcurrency, MaxConcurrency int
+	// MetricsInterval is the interval between two polls of the Chromium and LibreOffice queue sizes
+	// from the Prometheus metrics. Zero disables polling: limits then follow the responses only.
+	MetricsInterval time.Duration
+	// MaxQueueSize is the queue size above which the limit of a module is decreased;
+	// it defaults to MaxConcurrency.
+	MaxQueueSize float64
+	// MaxLatency is the latency above which a response counts as congestion; zero ignores latencies.
+	// Failed requests and 503 and 429 responses always count as congestion.
+	MaxLatency time.Duration
+}
+
+// Throttler limits the concurrent requests of a client to each Gotenberg module, Chromium,
+// LibreOffice and PDF engines, blocking Send until a slot is free. Limits follow an AIMD scheme:
+// they are halved when the module is congested and grow by one when it keeps up.
+//
+// Congestion is read from the queue sizes of the Prometheus metrics when they are polled and
+// available, otherwise from latencies and failures.
+type Throttler struct {
+	opts    ThrottleOptions
+	mu      sync.Mutex
+	modules map[string]*moduleLimiter
+}
+
+// moduleLimiter is the concurrency limit of a module.
+type moduleLimiter struct {
+	limit    float64
+	inFlight int
+	waiters  []chan struct{}
+	// metrics reports whether the limit follows the queue size of the last metrics poll.
+	metrics bool
+	// decreased is the time of the last decrease, the limit decreasing at most once per cooldown.
+	decreased time.Time
+}
+
+// Throttle enables adaptive throttling on the client and returns the throttler,
+// whose metrics polling stops when the context is done.
+// It must be called before the client sends requests.
+func (c *Client) Throttle(ctx context.Context, opts ThrottleOptions) *Throttler {
+	if opts.MinConcurrency <= 0 {
+		opts.MinConcurrency = 1
+	}
+	if opts.MaxConcurrency <= 0 {
+		opts.MaxConcurrency = 8
+	}
+	opts.MaxConcurrency = max(opts.MaxConcurrency, opts.MinConcurrency)
+	if opts.MaxQueueSize <= 0 {
+		opts.MaxQueueSize = float64(opts.MaxConcurrency)
+	}
+
+	t := &Throttler{opts: opts, modules: make(map[string]*moduleLimiter)}
+	c.throttler = t
+	if opts.MetricsInterval > 0 {
+		go t.poll(ctx, c)
+	}
+	return t
+}
+
+// Limit returns the current concurrency limit of a module: chromium, libreoffice or pdfengines.
+func (t *Throttler) Limit(module string) int {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return int(t.module(module).limit)
+}
+
+// InFlight returns the number of requests to a module being sent.
+func (t *Throttler) InFlight(module string) int {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return t.module(module).inFlight
+}
+
+// module returns the limiter of a module, creating it if needed; t.mu must be held.
+func (t *Throttler) module(name string) *moduleLimiter {
+	m, ok := t.modules[name]
+	if !ok {
+		m = &moduleLimiter{limit: float64(t.opts.MaxConcurrency)}
+		t.modules[name] = m
+	}
+	return m
+}
+
+// routeModule returns the module handling a route.
+func routeModule(route string) string {
+	module, _, _ := strings.Cut(strings.TrimPrefix(route, "/forms/"), "/")
+	return module
+}
+
+// acquire blocks until a request to the module may be sent, or the context is done.
+func (t *Throttler) acquire(ctx context.Context, module string) error {
+	t.mu.Lock()
+	m := t.module(module)
+	if m.inFlight < int(m.limit) {
+		m.inFlight++
+		t.mu.Unlock()
+		return nil
+	}
+	ready := make(chan struct{})
+	m.waiters = append(m.waiters, ready)
+	t.mu.Unlock()
+
+	select {
+	case <-ready:
+		return nil
+	case <-ctx.Done():
+		t.mu.Lock()
+		defer t.mu.Unlock()
+		if i := slices.Index(m.waiters, ready); i >= 0 {
+			m.waiters = slices.Delete(m.waiters, i, i+1)
+		} else {
+			// The slot was granted as the context was done: hand it over.
+			m.inFlight--
+			m.grant()
+		}
+		return ctx.Err()
+	}
+}
+
+// release frees the slot of a request and adjusts the limit of the module from its outcome.
+func (t *Throttler) release(module string, latency time.Duration, resp *http.Response, err error) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	m := t.module(module)
+	m.inFlight--
+	if !m.metrics {
+		congested := err != nil && !errors.Is(err, context.Canceled) ||
+			err == nil && (resp.StatusCode == http.StatusServiceUnavailable || resp.StatusCode == http.StatusTooManyRequests) ||
+			t.opts.MaxLatency > 0 && latency > t.opts.MaxLatency
+		switch {
+		case congested:
+			t.decrease(m)
+		case err == nil && resp.StatusCode < http.StatusInternalServerError:
+			// One more slot once a full window of requests has succeeded.
+			t.increase(m, 1/m.limit)
+		}
+	}
+	m.grant()
+}
+
+// increase raises the limit of a module by the given step, up to the maximum.
+func (t *Throttler) increase(m *moduleLimiter, step float64) {
+	m.limit = min(m.limit+step, float64(t.opts.MaxConcurrency))
+}
+
+// decrease halves the limit of a module, down to the minimum, at most once per cooldown:
+// the metrics interval, or a second.
+func (t *Throttler) decrease(m *moduleLimiter) {
+	cooldown := t.opts.MetricsInterval
+	if cooldown <= 0 {
+		cooldown = time.Second
+	}
+	if time.Since(m.decreased) < cooldown {
+		return
+	}
+	m.decreased = time.Now()
+	m.limit = max(float64(int(m.limit/2)), float64(t.opts.MinConcurrency))
+}
+
+// grant hands free slots over to waiting requests, in arrival order.
+func (m *moduleLimiter) grant() {
+	for m.inFlight < int(m.limit) && len(m.waiters) > 0 {
+		close(m.waiters[0])
+		m.waiters = m.waiters[1:]
+		m.inFlight++
+	}
+}
+
+// poll reads the queue sizes from the metrics every interval until the context is done.
+func (t *Throttler) poll(ctx context.Context, c *Client) {
+	ticker := time.NewTicker(t.opts.MetricsInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+
+		metrics, err := c.GetMetricFamilies(ctx)
+		queues := map[string]func() (float64, bool){
+			"chromium":    metrics.ChromiumQueueSize,
+			"libreoffice": metrics.LibreOfficeQueueSize,
+		}
+		t.mu.Lock()
+		for module, queueSize := range queues {
+			m := t.module(module)
+			size, ok := queueSize()
+			m.metrics = err == nil && ok
+			switch {
+			case !m.metrics:
+			case size > t.opts.MaxQueueSize:
+				t.decrease(m)
+			case m.inFlight >= int(m.limit):
+				t.increase(m, 1)
+			}
+			m.grant()
+		}
+		t.mu.Unlock()
+	}
+}
+
+// admit waits for a slot for the request when its client is throttled, or until the context is done,
+// and returns the function releasing it once the response is received.
+func (r *Request) admit(ctx context.Context) (func(*http.Response, error), error) {
+	if r.client == nil || r.client.throttler == nil {
+		return func(*http.Response, error) {}, nil
+	}
+
+	t, module := r.client.throttler, routeModule(r.route)
+	if err := t.acquire(ctx, module); err != nil {
+		return nil, err
+	}
+	start := time.Now()
+	return func(resp *http.Response, err error) {
+		t.release(module, time.Since(start), resp, err)
+	}, nil
+}
+
+// awaitInputs reads the first byte of the files that cannot be reread, such as the outputs of
+// pipeline stages, when the client is throttled: the request then takes its slot once its inputs
+// are being produced, rather than holding it while they wait for slots of their own.
+func (r *Request) awaitInputs(files []formFile) ([]formFile, error) {
+	if r.client == nil || r.client.throttler == nil {
+		return files, nil
+	}
+
+	files = slices.Clone(files)
+	for i, f := range files {
+		if rereadable(f.Content) {
+			continue
+		}
+		b := make([]byte, 1)
+		n, err := io.ReadFull(f.Content, b)
+		if err != nil && err != io.EOF {
+			return nil, fmt.Errorf("read file %s: %w", f.Name, err)
+		}
+		files[i].Content = io.MultiReader(bytes.NewReader(b[:n]), f.Content)
+	}
+	return files, nil
+}
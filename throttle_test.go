@@ -0,0 +1,170 @@
+package gotenberg
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"testing"
+	"time"
+)
+
+// outcome is the result of a throttled request.
+type outcome struct {
+	status  int
+	err     error
+	latency time.Duration
+}
+
+// throttler returns the throttler of a client that is never sent anything.
+func throttler(t *testing.T, opts ThrottleOptions) *Throttler {
+	t.Helper()
+	c, err := NewClient(http.DefaultClient, "http://gotenberg.invalid")
+	if err != nil {
+		t.Fatal(err)
+	}
+	return c.Throttle(context.Background(), opts)
+}
+
+func TestThrottlerRelease(t *testing.T) {
+	ok := outcome{status: http.StatusOK}
+	tests := []struct {
+		name     string
+		opts     ThrottleOptions
+		outcomes []outcome
+		want     int
+	}{
+		{name: "success at max", outcomes: []outcome{ok, ok}, want: 8},
+		{name: "unavailable", outcomes: []outcome{{status: http.StatusServiceUnavailable}}, want: 4},
+		{name: "too many requests", outcomes: []outcome{{status: http.StatusTooManyRequests}}, want: 4},
+		{name: "failure", outcomes: []outcome{{err: errors.New("connection reset")}}, want: 4},
+		{name: "cancelled", outcomes: []outcome{{err: context.Canceled}}, want: 8},
+		{name: "server error", outcomes: []outcome{{status: http.StatusInternalServerError}}, want: 8},
+		{
+			name:     "slow",
+			opts:     ThrottleOptions{MaxLatency: time.Second},
+			outcomes: []outcome{{status: http.StatusOK, latency: 2 * time.Second}},
+			want:     4,
+		},
+		{
+			name:     "once per cooldown",
+			opts:     ThrottleOptions{MetricsInterval: time.Hour},
+			outcomes: []outcome{{status: http.StatusServiceUnavailable}, {status: http.StatusServiceUnavailable}},
+			want:     4,
+		},
+		{
+			name:     "down to the minimum",
+			opts:     ThrottleOptions{MinConcurrency: 3, MaxConcurrency: 4},
+			outcomes: []outcome{{status: http.StatusServiceUnavailable}},
+			want:     3,
+		},
+		{
+			name:     "one more after a full window",
+			outcomes: []outcome{{status: http.StatusServiceUnavailable}, ok, ok, ok, ok, ok},
+			want:     5,
+		},
+		{
+			name:     "not before a full window",
+			outcomes: []outcome{{status: http.StatusServiceUnavailable}, ok, ok, ok},
+			want:     4,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			th := throttler(t, tt.opts)
+			for _, o := range tt.outcomes {
+				if err := th.acquire(context.Background(), "chromium"); err != nil {
+					t.Fatal(err)
+				}
+				var resp *http.Response
+				if o.err == nil {
+					resp = &http.Response{StatusCode: o.status}
+				}
+				th.release("chromium", o.latency, resp, o.err)
+			}
+			if got := th.Limit("chromium"); got != tt.want {
+				t.Errorf("Limit() = %d, want %d", got, tt.want)
+			}
+			if got := th.InFlight("chromium"); got != 0 {
+				t.Errorf("InFlight() = %d, want 0", got)
+			}
+		})
+	}
+}
+
+func TestThrottlerAcquireCancelled(t *testing.T) {
+	tests := []struct {
+		name string
+		// granted frees the slot as the context of the waiting request is cancelled.
+		granted bool
+	}{
+		{name: "waiting"},
+		{name: "granted", granted: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			th := throttler(t, ThrottleOptions{MinConcurrency: 1, MaxConcurrency: 1})
+			if err := th.acquire(context.Background(), "chromium"); err != nil {
+				t.Fatal(err)
+			}
+			waiters := func(n int) {
+				t.Helper()
+				for deadline := time.Now().Add(time.Second); ; time.Sleep(time.Millisecond) {
+					th.mu.Lock()
+					got := len(th.modules["chromium"].waiters)
+					th.mu.Unlock()
+					if got == n {
+						return
+					}
+					if time.Now().After(deadline) {
+						t.Fatalf("%d waiting requests, want %d", got, n)
+					}
+				}
+			}
+
+			ctx, cancel := context.WithCancel(context.Background())
+			cancelled := make(chan error, 1)
+			go func() { cancelled <- th.acquire(ctx, "chromium") }()
+			waiters(1)
+			next := make(chan error, 1)
+			go func() { next <- th.acquire(context.Background(), "chromium") }()
+			waiters(2)
+
+			th.mu.Lock()
+			cancel()
+			if tt.granted {
+				// Let the cancelled request wake up before its slot is granted.
+				time.Sleep(10 * time.Millisecond)
+				m := th.modules["chromium"]
+				m.inFlight--
+				m.grant()
+			}
+			th.mu.Unlock()
+
+			err := <-cancelled
+			switch {
+			case err == nil && tt.granted:
+				// The slot was granted before the cancellation was seen: the request holds it.
+				th.release("chromium", 0, &http.Response{StatusCode: http.StatusOK}, nil)
+			case errors.Is(err, context.Canceled) && tt.granted:
+				// The slot is handed over to the next request.
+			case errors.Is(err, context.Canceled):
+				th.release("chromium", 0, &http.Response{StatusCode: http.StatusOK}, nil)
+			default:
+				t.Fatalf("acquire() error = %v", err)
+			}
+			select {
+			case err := <-next:
+				if err != nil {
+					t.Fatalf("acquire() error = %v", err)
+				}
+			case <-time.After(time.Second):
+				t.Fatal("the next request did not get the slot")
+			}
+			if got := th.InFlight("chromium"); got != 1 {
+				t.Errorf("InFlight() = %d, want 1", got)
+			}
+		})
+	}
+}
@@ -0,0 +1,193 @@
+package gotenberg
+
+import (
+	"cmp"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// ErrUnsupportedByServer is returned by Send when the request uses a route or a form field
+// that the Gotenberg server is too old to support.
+var ErrUnsupportedByServer = errors.New("gotenberg: unsupported by server")
+
+// Version is a Gotenberg semantic version.
+type Version struct {
+	Major, Minor, Patch int
+}
+
+// ParseVersion parses a version such as "8.23.2", "v8.24" or "8.25.0-rc1".
+// Pre-release and build suffixes are ignored.
+func ParseVersion(s string) (Version, error) {
+	s = strings.TrimPrefix(strings.TrimSpace(s), "v")
+	core, _, _ := strings.Cut(s, "+")
+	core, _, _ = strings.Cut(core, "-")
+
+	parts := strings.Split(core, ".")
+	if len(parts) > 3 {
+		return Version{}, fmt.Errorf("invalid version %q", s)
+	}
+	var n [3]int
+	for i, p := range parts {
+		v, err := strconv.Atoi(p)
+		if err != nil || v < 0 {
+			return Version{}, fmt.Errorf("invalid version %q", s)
+		}
+		n[i] = v
+	}
+	return Version{Major: n[0], Minor: n[1], Patch: n[2]}, nil
+}
+
+// String returns the version as major.minor.patch.
+func (v Version) String() string {
+	return fmt.Sprintf("%d.%d.%d", v.Major, v.Minor, v.Patch)
+}
+
+// Compare returns -1, 0 or +1 depending on whether v is older than, equal to or newer than w.
+func (v Version) Compare(w Version) int {
+	return cmp.Or(cmp.Compare(v.Major, w.Major), cmp.Compare(v.Minor, w.Minor), cmp.Compare(v.Patch, w.Patch))
+}
+
+// VersionPolicy controls how Send handles features the server is too old to support.
+type VersionPolicy int
+
+const (
+	// VersionUnchecked sends every request as is, without querying the server version.
+	VersionUnchecked VersionPolicy = iota
+	// VersionStrict fails requests using an unsupported route or form field with ErrUnsupportedByServer.
+	VersionStrict
+	// VersionLenient drops unsupported form fields with a warning logged with slog;
+	// requests to an unsupported route still fail with ErrUnsupportedByServer.
+	VersionLenient
+)
+
+// openSince starts a request to a route that the server supports from the given version on.
+func (r *Request) openSince(ctx context.Context, route string, since Version) *Request {
+	r.open(ctx, route)
+	return r.requires(route, since)
+}
+
+// paramSince adds a form parameter that the server supports from the given version on.
+func (r *Request) paramSince(key, value string, since Version) *Request {
+	r.Param(key, value)
+	return r.requires(key, since)
+}
+
+// fileSince adds a file under a form field that the server supports from the given version on.
+func (r *Request) fileSince(fieldName, filename string, content io.Reader, since Version) *Request {
+	r.file(fieldName, filename, content)
+	return r.requires(fieldName, since)
+}
+
+// requires records the server version needed by a route or a form field.
+func (r *Request) requires(name string, since Version) *Request {
+	if r.since == nil {
+		r.since = make(map[string]Version)
+	}
+	r.since[name] = since
+	return r
+}
+
+// MinServerVersion returns the oldest server version supporting the route and every form field
+// of the request, and false if any Gotenberg 8 server supports them.
+func (r *Request) MinServerVersion() (Version, bool) {
+	var v Version
+	need := func(name string) {
+		if since, ok := r.since[name]; ok && since.Compare(v) > 0 {
+			v = since
+		}
+	}
+	need(r.route)
+	for _, p := range r.params {
+		need(p.key)
+	}
+	for _, f := range r.files {
+		if f.field != "files" {
+			need(f.field)
+		}
+	}
+	return v, v != Version{}
+}
+
+// VersionPolicy sets how requests using features the server is too old to support are handled.
+// Unless the policy is VersionUnchecked, the server version is queried once, on the first Send.
+func (c *Client) VersionPolicy(policy VersionPolicy) *Client {
+	c.policy = policy
+	return c
+}
+
+// SetServerVersion sets the server version, sparing the client from querying it.
+func (c *Client) SetServerVersion(v Version) *Client {
+	c.versionMu.Lock()
+	defer c.versionMu.Unlock()
+	c.version = &v
+	return c
+}
+
+// ServerVersion returns the server version, queried once and then cached.
+// A failed query is not cached.
+func (c *Client) ServerVersion(ctx context.Context) (Version, error) {
+	c.versionMu.Lock()
+	defer c.versionMu.Unlock()
+
+	if c.version != nil {
+		return *c.version, nil
+	}
+	s, err := c.GetVersion(ctx)
+	if err != nil {
+		return Version{}, fmt.Errorf("gotenberg: querying the server version: %w", err)
+	}
+	v, err := ParseVersion(s)
+	if err != nil {
+		return Version{}, fmt.Errorf("gotenberg: %w", err)
+	}
+	c.version = &v
+	return v, nil
+}
+
+// checkVersion checks the route and form fields of the request against the server version,
+// according to the policy of the client.
+func (r *Request) checkVersion() error {
+	if r.client == nil || r.client.policy == VersionUnchecked {
+		return nil
+	}
+	ctx := r.ctx
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	server, err := r.client.ServerVersion(ctx)
+	if err != nil {
+		return err
+	}
+
+	unsupported := func(name string) (Version, bool) {
+		since, ok := r.since[name]
+		return since, ok && server.Compare(since) < 0
+	}
+	if since, ok := unsupported(r.route); ok {
+		return fmt.Errorf("%w: %s requires Gotenberg %s, server runs %s", ErrUnsupportedByServer, r.route, since, server)
+	}
+
+	var errs []error
+	drop := func(name string) bool {
+		since, ok := unsupported(name)
+		if !ok {
+			return false
+		}
+		if r.client.policy == VersionStrict {
+			errs = append(errs, fmt.Errorf("%w: %s requires Gotenberg %s, server runs %s", ErrUnsupportedByServer, name, since, server))
+			return false
+		}
+		slog.WarnContext(ctx, "gotenberg: dropping a form field unsupported by the server",
+			"route", r.route, "field", name, "requires", since.String(), "server", server.String())
+		return true
+	}
+	r.params = slices.DeleteFunc(r.params, func(p formField) bool { return drop(p.key) })
+	r.files = slices.DeleteFunc(r.files, func(f formFile) bool { return f.field != "files" && drop(f.field) })
+	return errors.Join(errs...)
+}
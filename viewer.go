@@ -0,0 +1,132 @@
+package gotenberg
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+)
+
+// InitialView represents the panel shown when the PDF is opened.
+type InitialView int
+
+// Initial views.
+const (
+	InitialViewDefault InitialView = iota
+	InitialViewOutline
+	InitialViewThumbnails
+)
+
+// Magnification represents the magnification mode when the PDF is opened.
+type Magnification int
+
+// Magnification modes.
+const (
+	MagnificationDefault Magnification = iota
+	MagnificationFitWindow
+	MagnificationFitWidth
+	MagnificationFitVisible
+	MagnificationZoom
+)
+
+// PageLayout represents the page layout when the PDF is opened.
+type PageLayout int
+
+// Page layouts.
+const (
+	PageLayoutDefault PageLayout = iota
+	PageLayoutSinglePage
+	PageLayoutOneColumn
+	PageLayoutTwoColumns
+)
+
+// ViewerPreferences configures how PDF viewers display the exported PDF.
+// Zero-valued fields keep the viewer's defaults.
+type ViewerPreferences struct {
+	InitialView InitialView
+	// InitialPage is the 1-based page displayed when the PDF is opened.
+	InitialPage   int
+	Magnification Magnification
+	// Zoom is the zoom percentage, only used with MagnificationZoom.
+	Zoom       int
+	PageLayout PageLayout
+	// FirstPageOnLeft shows the first page on the left, only used with PageLayoutTwoColumns.
+	FirstPageOnLeft           bool
+	ResizeWindowToInitialPage bool
+	CenterWindow              bool
+	OpenInFullScreenMode      bool
+	DisplayPDFDocumentTitle   bool
+	HideViewerMenubar         bool
+	HideViewerToolbar         bool
+	HideViewerWindowControls  bool
+}
+
+// Validate checks the values and combinations of the preferences.
+func (v ViewerPreferences) Validate() error {
+	var errs []error
+
+	if v.InitialView < InitialViewDefault || v.InitialView > InitialViewThumbnails {
+		errs = append(errs, fmt.Errorf("unknown initial view %d", v.InitialView))
+	}
+	if v.InitialPage < 0 {
+		errs = append(errs, fmt.Errorf("invalid initial page %d", v.InitialPage))
+	}
+	if v.Magnification < MagnificationDefault || v.Magnification > MagnificationZoom {
+		errs = append(errs, fmt.Errorf("unknown magnification %d", v.Magnification))
+	}
+	switch {
+	case v.Magnification == MagnificationZoom && v.Zoom <= 0:
+		errs = append(errs, errors.New("zoom magnification requires a positive zoom"))
+	case v.Magnification != MagnificationZoom && v.Zoom != 0:
+		errs = append(errs, errors.New("zoom requires the zoom magnification"))
+	}
+	if v.PageLayout < PageLayoutDefault || v.PageLayout > PageLayoutTwoColumns {
+		errs = append(errs, fmt.Errorf("unknown page layout %d", v.PageLayout))
+	}
+	if v.FirstPageOnLeft && v.PageLayout != PageLayoutTwoColumns {
+		errs = append(errs, errors.New("first page on left requires the two columns page layout"))
+	}
+
+	return errors.Join(errs...)
+}
+
+// ViewerPreferences validates the preferences and applies them to the exported PDF.
+func (r *LibreOffice) ViewerPreferences(v ViewerPreferences) *LibreOffice {
+	if err := v.Validate(); err != nil {
+		r.fail(fmt.Errorf("viewer preferences: %w", err))
+		return r
+	}
+
+	for _, item := range []struct {
+		key string
+		val int
+	}{
+		{"initialView", int(v.InitialView)},
+		{"initialPage", v.InitialPage},
+		{"magnification", int(v.Magnification)},
+		{"zoom", v.Zoom},
+		{"pageLayout", int(v.PageLayout)},
+	} {
+		if item.val != 0 {
+			r.Param(item.key, strconv.Itoa(item.val))
+		}
+	}
+
+	for _, item := range []struct {
+		key string
+		val bool
+	}{
+		{"firstPageOnLeft", v.FirstPageOnLeft},
+		{"resizeWindowToInitialPage", v.ResizeWindowToInitialPage},
+		{"centerWindow", v.CenterWindow},
+		{"openInFullScreenMode", v.OpenInFullScreenMode},
+		{"displayPDFDocumentTitle", v.DisplayPDFDocumentTitle},
+		{"hideViewerMenubar", v.HideViewerMenubar},
+		{"hideViewerToolbar", v.HideViewerToolbar},
+		{"hideViewerWindowControls", v.HideViewerWindowControls},
+	} {
+		if item.val {
+			r.Bool(item.key, true)
+		}
+	}
+	return r
+}
@@ -0,0 +1,214 @@
+package gotenberg
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"regexp"
+	"strconv"
+)
+
+// WatermarkSource represents the kind of source used for a watermark or a stamp.
+type WatermarkSource string
+
+// Watermark and stamp sources.
+const (
+	WatermarkSourceText  WatermarkSource = "text"
+	WatermarkSourceImage WatermarkSource = "image"
+	WatermarkSourcePDF   WatermarkSource = "pdf"
+)
+
+// Position represents the anchor of a watermark or a stamp on the page.
+type Position string
+
+// Watermark and stamp positions.
+const (
+	PositionTopLeft      Position = "tl"
+	PositionTopCenter    Position = "tc"
+	PositionTopRight     Position = "tr"
+	PositionLeft         Position = "l"
+	PositionCenter       Position = "c"
+	PositionRight        Position = "r"
+	PositionBottomLeft   Position = "bl"
+	PositionBottomCenter Position = "bc"
+	PositionBottomRight  Position = "br"
+)
+
+// hexColor matches colors such as #F00 or #FF0000.
+var hexColor = regexp.MustCompile(`^#(?:[0-9a-fA-F]{3}|[0-9a-fA-F]{6})$`)
+
+// WatermarkOptions configures a watermark applied behind page content.
+// Zero-valued options are left to Gotenberg's defaults.
+type WatermarkOptions struct {
+	// Source is the kind of watermark: text, image or PDF.
+	Source WatermarkSource
+	// Expression is the text of a text watermark, or the filename of the image or PDF source.
+	// It defaults to the name of File.
+	Expression string
+	// File is the image or PDF source, uploaded along with the request.
+	File *NamedFile
+	// Pages restricts the watermark to the given pages, e.g. '1-5, 8' or 'odd'; see PageRanges.
+	Pages string
+	// Opacity is the opacity, from 0 to 1; nil leaves it to Gotenberg.
+	Opacity *float64
+	// Rotation is the rotation angle in degrees, from -360 to 360.
+	Rotation float64
+	// Scale is the scale factor relative to the page, from 0 to 1; nil leaves it to Gotenberg.
+	Scale *float64
+	// Position is the anchor of the watermark on the page.
+	Position Position
+	// OffsetX and OffsetY shift the watermark from its anchor, in points.
+	OffsetX, OffsetY float64
+	// Font is the font name of a text watermark.
+	Font string
+	// FontSize is the font size of a text watermark, in points.
+	FontSize int
+	// Color is the fill color of a text watermark, e.g. #808080.
+	Color string
+}
+
+// StampOptions configures a stamp applied on top of page content.
+// It accepts the same settings as WatermarkOptions.
+type StampOptions WatermarkOptions
+
+// Validate checks the consistency of the options.
+func (o WatermarkOptions) Validate() error {
+	var errs []error
+
+	switch o.Source {
+	case WatermarkSourceText:
+		if o.Expression == "" {
+			errs = append(errs, errors.New("text source requires an expression"))
+		}
+		if o.File != nil {
+			errs = append(errs, errors.New("text source does not accept a file"))
+		}
+	case WatermarkSourceImage, WatermarkSourcePDF:
+		if o.File == nil && o.Expression == "" {
+			errs = append(errs, fmt.Errorf("%s source requires a file", o.Source))
+		}
+		if o.Font != "" || o.FontSize != 0 || o.Color != "" {
+			errs = append(errs, fmt.Errorf("%s source does not accept font settings", o.Source))
+		}
+	default:
+		errs = append(errs, fmt.Errorf("unknown source %q", o.Source))
+	}
+
+	if o.Pages != "" {
+		if _, err := parsePageSelection(o.Pages); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	if o.Opacity != nil && (*o.Opacity < 0 || *o.Opacity > 1) {
+		errs = append(errs, fmt.Errorf("opacity %g out of range [0, 1]", *o.Opacity))
+	}
+	if o.Rotation < -360 || o.Rotation > 360 {
+		errs = append(errs, fmt.Errorf("rotation %g out of range [-360, 360]", o.Rotation))
+	}
+	if o.Scale != nil && (*o.Scale < 0 || *o.Scale > 1) {
+		errs = append(errs, fmt.Errorf("scale %g out of range [0, 1]", *o.Scale))
+	}
+	if o.FontSize < 0 {
+		errs = append(errs, fmt.Errorf("invalid font size %d", o.FontSize))
+	}
+	if o.Color != "" && !hexColor.MatchString(o.Color) {
+		errs = append(errs, fmt.Errorf("invalid color %q", o.Color))
+	}
+
+	switch o.Position {
+	case "", PositionTopLeft, PositionTopCenter, PositionTopRight,
+		PositionLeft, PositionCenter, PositionRight,
+		PositionBottomLeft, PositionBottomCenter, PositionBottomRight:
+	default:
+		errs = append(errs, fmt.Errorf("unknown position %q", o.Position))
+	}
+
+	return errors.Join(errs...)
+}
+
+// Validate checks the consistency of the options.
+func (o StampOptions) Validate() error {
+	return WatermarkOptions(o).Validate()
+}
+
+// options returns the engine options as a JSON object, or an empty string if none are set.
+func (o WatermarkOptions) options() (string, error) {
+	opts := make(map[string]any)
+	if o.Opacity != nil {
+		opts["opacity"] = *o.Opacity
+	}
+	if o.Rotation != 0 {
+		opts["rotation"] = o.Rotation
+	}
+	if o.Scale != nil {
+		opts["scalefactor"] = *o.Scale
+	}
+	if o.Position != "" {
+		opts["position"] = o.Position
+	}
+	if o.OffsetX != 0 || o.OffsetY != 0 {
+		opts["offset"] = strconv.FormatFloat(o.OffsetX, 'f', -1, 64) + " " + strconv.FormatFloat(o.OffsetY, 'f', -1, 64)
+	}
+	if o.Font != "" {
+		opts["fontname"] = o.Font
+	}
+	if o.FontSize != 0 {
+		opts["points"] = o.FontSize
+	}
+	if o.Color != "" {
+		opts["fillcolor"] = o.Color
+	}
+	if len(opts) == 0 {
+		return "", nil
+	}
+
+	b, err := json.Marshal(opts)
+	return string(b), err
+}
+
+// overlay validates the options and applies them to the request,
+// using prefix as the form field name for the source file and as the prefix of the other fields.
+func (r *Request) overlay(prefix string, o WatermarkOptions) *Request {
+	if err := o.Validate(); err != nil {
+		return r.fail(fmt.Errorf("%s: %w", prefix, err))
+	}
+
+	opts, err := o.options()
+	if err != nil {
+		return r.fail(fmt.Errorf("%s: %w", prefix, err))
+	}
+
+	expression := o.Expression
+	if expression == "" && o.File != nil {
+		expression = o.File.Name
+	}
+
+	// Watermarks and stamps are supported from Gotenberg 8.26.0 on.
+	since := Version{8, 26, 0}
+	r.paramSince(prefix+"Source", string(o.Source), since)
+	r.paramSince(prefix+"Expression", expression, since)
+	if o.Pages != "" {
+		r.paramSince(prefix+"Pages", o.Pages, since)
+	}
+	if opts != "" {
+		r.paramSince(prefix+"Options", opts, since)
+	}
+	if o.File != nil {
+		r.fileSince(prefix, o.File.Name, o.File.Content, since)
+	}
+	return r
+}
+
+// WatermarkOptions validates the options and applies a watermark to the route.
+// Besides the watermark route, it is honored by the merge and split routes. It requires Gotenberg 8.26.0.
+func (r *PDFEngines) WatermarkOptions(o WatermarkOptions) *PDFEngines {
+	r.Request.overlay("watermark", o)
+	return r
+}
+
+// StampOptions validates the options and applies a stamp to the route.
+// Besides the stamp route, it is honored by the merge and split routes. It requires Gotenberg 8.26.0.
+func (r *PDFEngines) StampOptions(o StampOptions) *PDFEngines {
+	r.Request.overlay("stamp", WatermarkOptions(o))
+	return r
+}